@@ -0,0 +1,406 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/lucianoayres/nino-cli/internal/client"
+	"github.com/lucianoayres/nino-cli/internal/config"
+	"github.com/lucianoayres/nino-cli/internal/logger"
+	"github.com/lucianoayres/nino-cli/internal/models"
+	"github.com/lucianoayres/nino-cli/internal/processor"
+	"github.com/lucianoayres/nino-cli/internal/session"
+	"github.com/lucianoayres/nino-cli/internal/terminal"
+	"github.com/lucianoayres/nino-cli/internal/utils"
+)
+
+const chatHelp = `Commands:
+  /model [NAME]    Show the model, or switch to another one
+  /system [TEXT]   Show the system prompt, or set it ("/system off" removes it)
+  /image PATH      Attach an image to the next message
+  /retry           Send the last message again, replacing its reply
+  /clear           Forget the messages of the conversation
+  /save NAME       Save the conversation as a session, and the next messages with it
+  /help            Show this help
+  /exit            Quit, like Ctrl-D
+
+Enclose a message of several lines in """, or end its lines with \.
+Ctrl-C stops the reply being generated.
+`
+
+// chat is the state of an interactive chat.
+type chat struct {
+	cfg      *config.Config
+	cli      *client.HTTPClient
+	log      *logger.Logger
+	editor   *terminal.Editor
+	history  *terminal.History
+	format   json.RawMessage
+	model    string
+	system   string
+	session  string           // The session the messages are saved to, if any
+	messages []models.Message // The conversation, without the system prompt
+	images   []string         // The images attached to the next message
+	failed   *models.Message  // The last message, when it got no reply
+}
+
+// runChat runs an interactive chat until the user quits, starting with the prompt
+// of the configuration if there is one, and returns the exit code.
+func runChat(cfg *config.Config, cli *client.HTTPClient, log *logger.Logger, images []string) int {
+	c := &chat{
+		cfg:     cfg,
+		cli:     cli,
+		log:     log,
+		model:   cfg.Model,
+		system:  cfg.System,
+		session: cfg.Session,
+		images:  images,
+	}
+	if cfg.Schema != nil {
+		c.format, _ = json.Marshal(cfg.Schema)
+	} else if cfg.Format != "" {
+		c.format, _ = json.Marshal(cfg.Format)
+	}
+
+	history, err := loadInputHistory()
+	if err != nil {
+		log.Error("Input history not available: %v", err)
+		history, _ = terminal.LoadHistory("")
+	}
+	c.history = history
+	c.editor = terminal.NewEditor(os.Stdin, os.Stdout, history)
+
+	if c.session != "" {
+		c.messages, err = session.Load(c.session)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading session: %v\n", err)
+			return 1
+		}
+	}
+	if c.editor.Terminal() {
+		fmt.Printf("Chatting with %s. Type /help for the commands, /exit or Ctrl-D to quit.\n", c.model)
+		if c.session != "" {
+			fmt.Printf("Continuing session %s (%d messages).\n", c.session, len(c.messages))
+		}
+		fmt.Println()
+	}
+
+	if strings.TrimSpace(cfg.Prompt) != "" {
+		c.send(models.Message{Role: "user", Content: cfg.Prompt})
+	}
+	for {
+		input, err := c.readInput()
+		if err == io.EOF {
+			return 0
+		}
+		if err == terminal.ErrInterrupted {
+			fmt.Println("(Type /exit or press Ctrl-D to quit)")
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+			return 1
+		}
+
+		switch text := strings.TrimSpace(input); {
+		case text == "":
+		case strings.HasPrefix(text, "/"):
+			if quit := c.command(text); quit {
+				return 0
+			}
+		default:
+			c.send(models.Message{Role: "user", Content: input})
+		}
+	}
+}
+
+// loadInputHistory loads the history of the lines entered in the chats.
+func loadInputHistory() (*terminal.History, error) {
+	dataDir, err := utils.GetNinoDataDir()
+	if err != nil {
+		return nil, err
+	}
+	return terminal.LoadHistory(filepath.Join(dataDir, "history"))
+}
+
+// readLine reads a line and adds it to the input history.
+func (c *chat) readLine(prompt string) (string, error) {
+	line, err := c.editor.ReadLine(prompt)
+	if err != nil {
+		return "", err
+	}
+	if err := c.history.Add(line); err != nil {
+		c.log.Error("Failed to save the input history: %v", err)
+	}
+	return line, nil
+}
+
+// readInput reads a message, which spans several lines when it is enclosed in
+// triple quotes or when its lines end with a backslash.
+func (c *chat) readInput() (string, error) {
+	line, err := c.readLine(">>> ")
+	if err != nil {
+		return "", err
+	}
+
+	if first, ok := strings.CutPrefix(strings.TrimSpace(line), `"""`); ok {
+		if text, ok := strings.CutSuffix(first, `"""`); ok {
+			return text, nil
+		}
+		lines := []string{first}
+		for {
+			line, err := c.readLine("... ")
+			if err != nil {
+				return "", err
+			}
+			if last, ok := strings.CutSuffix(strings.TrimRight(line, " \t"), `"""`); ok {
+				return strings.TrimSpace(strings.Join(append(lines, last), "\n")), nil
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	var lines []string
+	for {
+		text, continued := strings.CutSuffix(line, `\`)
+		lines = append(lines, text)
+		if !continued {
+			return strings.Join(lines, "\n"), nil
+		}
+		if line, err = c.readLine("... "); err != nil {
+			return "", err
+		}
+	}
+}
+
+// command runs a slash command and reports whether the chat should end.
+func (c *chat) command(input string) bool {
+	name, arg, _ := strings.Cut(input, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "/exit", "/quit", "/bye":
+		return true
+	case "/help", "/?":
+		fmt.Print(chatHelp)
+	case "/model":
+		if arg == "" {
+			fmt.Printf("Model: %s\n", c.model)
+		} else {
+			c.model = arg
+			fmt.Printf("Switched to model %s.\n", c.model)
+		}
+	case "/system":
+		switch arg {
+		case "":
+			if c.system == "" {
+				fmt.Println("No system prompt.")
+			} else {
+				fmt.Printf("System prompt: %s\n", c.system)
+			}
+		case "off":
+			c.system = ""
+			fmt.Println("System prompt removed.")
+		default:
+			c.system = arg
+			fmt.Println("System prompt set.")
+		}
+	case "/image":
+		if arg == "" {
+			fmt.Println("Usage: /image PATH")
+			break
+		}
+		path := strings.Trim(arg, `"'`)
+		images, err := utils.ReadImagesAsBase64([]string{path})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			break
+		}
+		c.images = append(c.images, images...)
+		fmt.Printf("Attached %s to the next message.\n", path)
+	case "/retry":
+		c.retry()
+	case "/clear":
+		c.messages, c.images, c.failed = nil, nil, nil
+		if c.session != "" {
+			fmt.Printf("Conversation cleared. Session %s is left as is, use /save to save the new conversation.\n", c.session)
+			c.session = ""
+		} else {
+			fmt.Println("Conversation cleared.")
+		}
+	case "/save":
+		if err := c.save(arg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	default:
+		fmt.Printf("Unknown command %s. Type /help for the commands.\n", name)
+	}
+	return false
+}
+
+// retry sends the last message again, dropping its reply from the conversation.
+func (c *chat) retry() {
+	message := c.failed
+	if message == nil {
+		last := -1
+		for i, m := range c.messages {
+			if m.Role == "user" {
+				last = i
+			}
+		}
+		if last < 0 {
+			fmt.Println("There is no message to send again.")
+			return
+		}
+		message = &c.messages[last]
+		c.messages = c.messages[:last]
+		if c.session != "" {
+			if err := session.Save(c.session, c.messages); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving session: %v\n", err)
+				return
+			}
+		}
+	}
+	c.send(*message)
+}
+
+// save saves the conversation as a new session, which the next messages are added to.
+func (c *chat) save(name string) error {
+	if name == "" {
+		return errors.New("usage: /save NAME")
+	}
+	if name == c.session {
+		fmt.Printf("The conversation is already saved in session %s.\n", name)
+		return nil
+	}
+	existing, err := session.Load(name)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("session '%s' already exists", name)
+	}
+	if err := session.Save(name, c.messages); err != nil {
+		return err
+	}
+	c.session = name
+	fmt.Printf("Saved %d messages to session %s.\n", len(c.messages), name)
+	return nil
+}
+
+// send sends a message with the attached images and prints the reply. The message
+// and its reply are added to the conversation, and saved to the session if any.
+func (c *chat) send(message models.Message) {
+	if len(c.images) > 0 {
+		message.Images = append(message.Images, c.images...)
+		c.images = nil
+	}
+
+	messages := append(append([]models.Message(nil), c.messages...), message)
+	reply, err := c.reply(messages)
+	interrupted := errors.Is(err, context.Canceled)
+	switch {
+	case interrupted && reply.Content != "":
+		// Keep the part of the reply that was printed
+		fmt.Print("[interrupted]\n\n")
+	case interrupted:
+		fmt.Print("[interrupted]\n\n")
+		c.failed = &message
+		return
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+		c.failed = &message
+		return
+	default:
+		fmt.Println()
+	}
+
+	c.failed = nil
+	c.messages = append(c.messages, message, reply)
+	if c.session != "" {
+		if err := session.Append(c.session, message, reply); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving session: %v\n", err)
+		}
+	}
+}
+
+// reply sends the conversation to the chat endpoint and streams the reply to stdout,
+// showing the loading animation until it starts. Ctrl-C cancels the request, in which
+// case it returns the part of the reply that was read and context.Canceled.
+func (c *chat) reply(messages []models.Message) (models.Message, error) {
+	if c.system != "" {
+		messages = append([]models.Message{{Role: "system", Content: c.system}}, messages...)
+	}
+	payload := models.ChatRequestPayload{
+		Model:      c.model,
+		Messages:   messages,
+		Format:     c.format,
+		Stream:     c.cfg.Stream,
+		Keep_Alive: c.cfg.Keep_Alive,
+		Options:    c.cfg.Options,
+	}
+
+	// Cancel the request on Ctrl-C instead of quitting
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	go func() {
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// Show the loading animation until the first token is written
+	stopAnimation := func() {}
+	if !c.cfg.DisableLoading {
+		stopAnimation = startAnimation()
+	}
+
+	c.log.Info("Sending %d message(s) to %s", len(payload.Messages), c.model)
+	response, err := c.cli.SendChatRequestContext(ctx, payload)
+	if err == nil {
+		err = checkStatus(response)
+	}
+	if err != nil {
+		stopAnimation()
+		if ctx.Err() != nil {
+			return models.Message{}, context.Canceled
+		}
+		return models.Message{}, err
+	}
+	defer response.Body.Close()
+
+	var content strings.Builder
+	w := &notifyWriter{w: io.MultiWriter(os.Stdout, &content), notify: stopAnimation}
+	message, err := processor.ProcessChatResponse(response.Body, w)
+	stopAnimation()
+	if content.Len() > 0 {
+		fmt.Println()
+	}
+	if err != nil && ctx.Err() != nil {
+		return models.Message{Role: "assistant", Content: content.String()}, context.Canceled
+	}
+	return message, err
+}
+
+// notifyWriter calls notify before each write to w.
+type notifyWriter struct {
+	w      io.Writer
+	notify func()
+}
+
+func (n *notifyWriter) Write(p []byte) (int, error) {
+	n.notify()
+	return n.w.Write(p)
+}
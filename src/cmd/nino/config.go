@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lucianoayres/nino-cli/internal/config"
+	"github.com/lucianoayres/nino-cli/internal/logger"
+	"github.com/lucianoayres/nino-cli/internal/utils"
+)
+
+const configUsage = `Usage: nino config <command>
+
+Commands:
+  show   Print the effective value of each setting and where it comes from
+  path   Print the paths of the configuration files and the data directory
+
+Settings come, by precedence, from the command-line flags, the selected profile
+(see 'nino profile'), the environment variables, the project configuration file,
+the user configuration file ($XDG_CONFIG_HOME/nino/config.toml, or config.yaml)
+and the defaults.
+
+The project configuration file is the nearest .nino.toml (or .nino/config.toml)
+found from the working directory up to the git root or the home directory.
+`
+
+// runConfigCommand runs the "nino config" subcommands and returns the exit code.
+func runConfigCommand(args []string) int {
+	logger.GetLogger(false)
+
+	if len(args) != 1 {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 1
+	}
+
+	var err error
+	switch args[0] {
+	case "show":
+		err = showConfig()
+	case "path":
+		err = showConfigPaths()
+	case "help", "-h", "-help":
+		fmt.Print(configUsage)
+	default:
+		fmt.Fprint(os.Stderr, configUsage)
+		return 1
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// showConfig prints the effective settings with their sources.
+func showConfig() error {
+	settings, err := config.Settings(nil)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "SETTING\tVALUE\tSOURCE")
+	for _, setting := range settings {
+		value := setting.Value
+		if value == "" {
+			value = "(not set)"
+		} else if strings.ContainsAny(value, "\t\n") || strings.TrimSpace(value) != value {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Name, value, setting.Source)
+	}
+	return w.Flush()
+}
+
+// showConfigPaths prints the paths of the configuration files and of the data directory.
+func showConfigPaths() error {
+	userFile, err := config.UserConfigFile()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(userFile); err != nil {
+		userFile += " (not found)"
+	}
+	projectFile := config.ProjectConfigFile()
+	if projectFile == "" {
+		projectFile = "(none found)"
+	}
+	utils.SetDataScope(config.DefaultDataScope())
+	dataDir, err := utils.GetNinoDataDir()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("user     %s\nproject  %s\ndata     %s\n", userFile, projectFile, dataDir)
+	return nil
+}
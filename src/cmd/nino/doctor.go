@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lucianoayres/nino-cli/internal/client"
+	"github.com/lucianoayres/nino-cli/internal/config"
+	"github.com/lucianoayres/nino-cli/internal/logger"
+	"github.com/lucianoayres/nino-cli/internal/utils"
+)
+
+const doctorUsage = `Usage: nino doctor [flags]
+
+Checks the Ollama server, the configured model and the local data directory,
+printing how to fix any problem found. Exits with a non-zero status when a
+check fails, so it can be used as a preflight step in scripts and CI.
+
+Flags:
+`
+
+// doctor prints the result of each check and counts the failures.
+type doctor struct {
+	w        io.Writer
+	failures int
+}
+
+// ok reports a passing check.
+func (d *doctor) ok(format string, args ...interface{}) {
+	fmt.Fprintf(d.w, "[ OK ] %s\n", fmt.Sprintf(format, args...))
+}
+
+// info reports a value that is neither passing nor failing.
+func (d *doctor) info(format string, args ...interface{}) {
+	fmt.Fprintf(d.w, "       %s\n", fmt.Sprintf(format, args...))
+}
+
+// fail reports a failing check and how to fix it.
+func (d *doctor) fail(fix, format string, args ...interface{}) {
+	d.failures++
+	fmt.Fprintf(d.w, "[FAIL] %s\n", fmt.Sprintf(format, args...))
+	for _, line := range strings.Split(fix, "\n") {
+		fmt.Fprintf(d.w, "       -> %s\n", line)
+	}
+}
+
+// runDoctorCommand runs the "nino doctor" diagnostics and returns the exit code.
+func runDoctorCommand(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	url := fs.String("url", config.DefaultURL(), "The URL of the Ollama server")
+	fs.StringVar(url, "u", config.DefaultURL(), "The URL of the Ollama server (short form)")
+	model := fs.String("model", config.DefaultModel(), "The model to check")
+	fs.StringVar(model, "m", config.DefaultModel(), "The model to check (short form)")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging")
+	fs.BoolVar(verbose, "v", false, "Enable verbose logging (short form)")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), doctorUsage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitCodeForFlagError(err)
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 1
+	}
+
+	// Settings given on the command line are reported with the flag as their source
+	overrides := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "url", "u":
+			overrides["url"] = *url
+		case "model", "m":
+			overrides["model"] = *model
+		}
+	})
+
+	logger.GetLogger(*verbose)
+	utils.SetDataScope(config.DefaultDataScope())
+	d := &doctor{w: os.Stdout}
+
+	fmt.Fprintln(d.w, "Ollama server")
+	headers := config.DefaultHeaders()
+	if d.checkServer(*url, headers) {
+		cli := client.NewHTTPClient(*url)
+		cli.Headers = headers
+		d.checkModel(cli, *model)
+		d.checkRunningModels(cli)
+	}
+
+	fmt.Fprintln(d.w, "\nConfiguration")
+	settings, err := config.Settings(overrides)
+	if err != nil {
+		d.fail("Fix the configuration file or run 'nino config show' for details", "%v", err)
+	}
+	for _, setting := range settings {
+		value := setting.Value
+		if value == "" {
+			value = "(not set)"
+		} else if strings.Contains(value, "\n") || len(value) > 60 {
+			value = fmt.Sprintf("(%d characters)", len(value))
+		}
+		d.info("%-12s %-40s %s", setting.Name, value, setting.Source)
+	}
+
+	fmt.Fprintln(d.w, "\nData directory")
+	d.checkDataDir()
+
+	if d.failures > 0 {
+		fmt.Fprintf(d.w, "\n%d check(s) failed.\n", d.failures)
+		return 1
+	}
+	fmt.Fprintln(d.w, "\nAll checks passed.")
+	return 0
+}
+
+// checkServer checks that an Ollama server answers at the URL and reports its version and latency.
+func (d *doctor) checkServer(url string, headers map[string]string) bool {
+	start := time.Now()
+	version, err := utils.CheckOllamaServer(url, headers)
+	latency := time.Since(start)
+	if err != nil {
+		// Connection errors mean nothing answers; any other error comes from a server that isn't Ollama
+		fix := "Something is listening at this URL, but it is not an Ollama server.\nCheck the port and any reverse proxy in front of Ollama, or set NINO_URL to the right URL."
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			fix = "Start the server with: ollama serve\nOr set NINO_URL (or -url) to the URL of a running server."
+		}
+		d.fail(fix, "Ollama server not reachable at %s: %v", url, err)
+		return false
+	}
+	d.ok("Ollama %s at %s (%s)", version, url, latency.Round(time.Millisecond))
+	return true
+}
+
+// checkModel checks that the model is available on the server.
+func (d *doctor) checkModel(cli *client.HTTPClient, model string) {
+	list, err := cli.ListModels()
+	if err != nil {
+		d.fail("Check the server logs for errors.", "Unable to list the models: %v", err)
+		return
+	}
+	for _, m := range list {
+		if modelMatches(model, m.Name) {
+			d.ok("Model %s is available (%s, %s)", model, m.Details.ParameterSize, formatBytes(m.Size))
+			return
+		}
+	}
+	d.fail(fmt.Sprintf("Download it with: nino models pull %s\nOr set NINO_MODEL (or -model) to one of the %d available model(s).", model, len(list)),
+		"Model %s is not available on the server", model)
+}
+
+// checkRunningModels reports the models loaded in memory.
+func (d *doctor) checkRunningModels(cli *client.HTTPClient) {
+	running, err := cli.ListRunningModels()
+	if err != nil {
+		d.fail("Check the server logs for errors.", "Unable to list the loaded models: %v", err)
+		return
+	}
+	if len(running) == 0 {
+		d.info("No models loaded in memory")
+		return
+	}
+	names := make([]string, 0, len(running))
+	for _, m := range running {
+		names = append(names, fmt.Sprintf("%s (%s)", m.Name, formatProcessor(m.Size, m.SizeVRAM)))
+	}
+	d.info("Loaded models: %s", strings.Join(names, ", "))
+}
+
+// checkDataDir checks that the data directory can be written and reports what it holds.
+func (d *doctor) checkDataDir() {
+	dir, err := utils.GetNinoDataDir()
+	if err != nil {
+		d.fail("Set XDG_DATA_HOME to a writable directory.", "Unable to determine the data directory: %v", err)
+		return
+	}
+
+	info, err := os.Stat(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		d.ok("%s does not exist yet, it will be created on first use", dir)
+		return
+	}
+	if err != nil {
+		d.fail("Check the permissions of the directory.", "Unable to access %s: %v", dir, err)
+		return
+	}
+	if !info.IsDir() {
+		d.fail("Move the file away or set XDG_DATA_HOME to another directory.", "%s is not a directory", dir)
+		return
+	}
+
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		d.fail(fmt.Sprintf("Fix the permissions with: chmod u+rwx %s", dir), "%s is not writable: %v", dir, err)
+		return
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	d.ok("%s is writable", dir)
+	d.info("Saved contexts: %d model(s)", countEntries(filepath.Join(dir, "models")))
+	d.info("Chat sessions:  %d", countEntries(filepath.Join(dir, "sessions")))
+}
+
+// modelMatches reports whether the configured model name refers to the listed model,
+// which always carries a tag (e.g. "llama3.2" matches "llama3.2:latest").
+func modelMatches(name, listed string) bool {
+	if !strings.Contains(name, ":") {
+		name += ":latest"
+	}
+	return name == listed
+}
+
+// countEntries returns the number of entries in a directory, or 0 if it can't be read.
+func countEntries(dir string) int {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	return len(entries)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/lucianoayres/nino-cli/internal/client"
+	"github.com/lucianoayres/nino-cli/internal/config"
+	"github.com/lucianoayres/nino-cli/internal/logger"
+	"github.com/lucianoayres/nino-cli/internal/models"
+	"github.com/lucianoayres/nino-cli/internal/processor"
+	"github.com/lucianoayres/nino-cli/internal/utils"
+)
+
+// generator sends the prompt to the generate or chat endpoint and processes the response.
+type generator struct {
+	ctx            context.Context // Canceling it aborts the request and the reading of the response
+	cfg            *config.Config
+	cli            *client.HTTPClient
+	log            *logger.Logger
+	payload        models.RequestPayload
+	chatPayload    models.ChatRequestPayload
+	contextHandler func([]int) error
+}
+
+// send sends the request, showing the loading animation while waiting for the response.
+func (g *generator) send() (*http.Response, error) {
+	// Start the loading animation if not disabled and not in silent mode
+	stopAnimation := func() {}
+	if !g.cfg.DisableLoading && !g.cfg.Silent {
+		stopAnimation = startAnimation()
+	}
+
+	// Send the HTTP request
+	g.log.StartTimer("Send HTTP Request")
+	g.log.Info("Sending HTTP request to Ollama server")
+	var response *http.Response
+	var err error
+	if g.cfg.Chat {
+		response, err = g.cli.SendChatRequestContext(g.ctx, g.chatPayload)
+	} else {
+		response, err = g.cli.SendRequestContext(g.ctx, g.payload)
+	}
+	g.log.StopTimer("Send HTTP Request")
+	stopAnimation()
+
+	if err != nil {
+		return nil, err
+	}
+	g.log.Info("Received response with status code: %d", response.StatusCode)
+
+	if err := checkStatus(response); err != nil {
+		return nil, err
+	}
+	g.log.Info("HTTP request successful")
+	return response, nil
+}
+
+// startAnimation shows the loading animation until the returned function is called.
+// The function can be called several times, and returns once the animation has
+// cleared its line, so that nothing written after it is erased.
+func startAnimation() (stop func()) {
+	done, stopped := make(chan bool), make(chan struct{})
+	go func() {
+		utils.ShowLoadingAnimation(done)
+		close(stopped)
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			done <- true
+			<-stopped
+		})
+	}
+}
+
+// checkStatus returns an error with the response body, which it closes, when the
+// response status is not OK.
+func checkStatus(response *http.Response) error {
+	if response.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		return fmt.Errorf("received HTTP status %d\nResponse body: %s", response.StatusCode, string(bodyBytes))
+	}
+	return nil
+}
+
+// process processes the response body, writing the output to w, and returns the
+// assistant message.
+func (g *generator) process(body io.Reader, w io.Writer) (models.Message, error) {
+	if g.cfg.Chat {
+		return processor.ProcessChatResponse(body, w)
+	}
+
+	var content strings.Builder
+	err := processor.ProcessResponse(body, io.MultiWriter(w, &content), g.contextHandler)
+	return models.Message{Role: "assistant", Content: content.String()}, err
+}
+
+// processValidated buffers each response until it is valid JSON, conforming to the
+// schema if one is set. Invalid outputs are sent back to the model with the validation
+// error, up to the configured number of attempts. Only the first valid output is
+// written to w.
+func (g *generator) processValidated(response *http.Response, w io.Writer) (models.Message, error) {
+	for attempt := 1; ; attempt++ {
+		g.log.Info("Processing attempt %d of %d", attempt, g.cfg.MaxAttempts)
+
+		var output bytes.Buffer
+		message, err := g.process(response.Body, &output)
+		response.Body.Close()
+		if err != nil {
+			return message, err
+		}
+
+		validationErr := processor.ValidateOutput(output.Bytes(), g.cfg.Schema)
+		if validationErr == nil {
+			_, err := w.Write(output.Bytes())
+			return message, err
+		}
+		if attempt >= g.cfg.MaxAttempts {
+			return message, fmt.Errorf("no valid output after %d attempts: %w", attempt, validationErr)
+		}
+
+		g.log.Info("Attempt %d produced invalid output: %v", attempt, validationErr)
+		g.addFeedback(message, validationErr)
+
+		response, err = g.send()
+		if err != nil {
+			return message, err
+		}
+	}
+}
+
+// addFeedback updates the request so that the next attempt tells the model why its
+// previous output was rejected.
+func (g *generator) addFeedback(invalid models.Message, validationErr error) {
+	feedback := fmt.Sprintf("Your previous response was invalid: %v. Respond again with only the corrected JSON, without any explanation.", validationErr)
+
+	if g.cfg.Chat {
+		g.chatPayload.Messages = append(g.chatPayload.Messages, invalid, models.Message{Role: "user", Content: feedback})
+		return
+	}
+	g.payload.Prompt = fmt.Sprintf("%s\n\nYour previous response was:\n%s\n\n%s", g.cfg.Prompt, invalid.Content, feedback)
+}
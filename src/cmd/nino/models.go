@@ -0,0 +1,244 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	neturl "net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lucianoayres/nino-cli/internal/client"
+	"github.com/lucianoayres/nino-cli/internal/config"
+	"github.com/lucianoayres/nino-cli/internal/logger"
+	"github.com/lucianoayres/nino-cli/internal/models"
+)
+
+const modelsUsage = `Usage: nino models <command> [flags] [arguments]
+
+Commands:
+  list                 List the models available on the server
+  show NAME            Show the details of a model
+  pull NAME            Download a model, showing its progress
+  rm NAME              Delete a model
+  ps                   List the models loaded in memory
+
+Flags:
+`
+
+// runModelsCommand runs the "nino models" subcommands and returns the exit code.
+func runModelsCommand(args []string) int {
+	fs := flag.NewFlagSet("models", flag.ContinueOnError)
+	url := fs.String("url", config.DefaultURL(), "The URL of the Ollama server")
+	fs.StringVar(url, "u", config.DefaultURL(), "The URL of the Ollama server (short form)")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging")
+	fs.BoolVar(verbose, "v", false, "Enable verbose logging (short form)")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), modelsUsage)
+		fs.PrintDefaults()
+	}
+
+	// Flags are accepted both before and after the command name
+	if err := fs.Parse(args); err != nil {
+		return exitCodeForFlagError(err)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 1
+	}
+	command := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return exitCodeForFlagError(err)
+	}
+	rest := fs.Args()
+
+	logger.GetLogger(*verbose)
+	cli := client.NewHTTPClient(*url)
+	cli.Headers = config.DefaultHeaders()
+
+	var err error
+	switch {
+	case command == "list" && len(rest) == 0:
+		err = listModels(cli, os.Stdout)
+	case command == "show" && len(rest) == 1:
+		err = showModel(cli, os.Stdout, rest[0])
+	case command == "pull" && len(rest) == 1:
+		err = pullModel(cli, os.Stdout, rest[0])
+	case command == "rm" && len(rest) == 1:
+		if err = cli.DeleteModel(rest[0]); err == nil {
+			fmt.Printf("Deleted model %s\n", rest[0])
+		}
+	case command == "ps" && len(rest) == 0:
+		err = listRunningModels(cli, os.Stdout)
+	default:
+		fs.Usage()
+		return 1
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			fmt.Fprintf(os.Stderr, "Is the Ollama server running at %s? Set the URL with -url or NINO_URL.\n", *url)
+		}
+		return 1
+	}
+	return 0
+}
+
+// exitCodeForFlagError returns the exit code for a flag parsing error; asking for help is not a failure.
+func exitCodeForFlagError(err error) int {
+	if err == flag.ErrHelp {
+		return 0
+	}
+	return 1
+}
+
+// listModels prints the models available on the server.
+func listModels(cli *client.HTTPClient, w io.Writer) error {
+	list, err := cli.ListModels()
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		fmt.Fprintln(w, "No models found. Download one with: nino models pull llama3.2")
+		return nil
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSIZE\tPARAMETERS\tQUANTIZATION\tMODIFIED")
+	for _, m := range list {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", m.Name, formatBytes(m.Size), m.Details.ParameterSize, m.Details.QuantizationLevel, formatTimestamp(m.ModifiedAt))
+	}
+	return tw.Flush()
+}
+
+// listRunningModels prints the models loaded in memory.
+func listRunningModels(cli *client.HTTPClient, w io.Writer) error {
+	running, err := cli.ListRunningModels()
+	if err != nil {
+		return err
+	}
+	if len(running) == 0 {
+		fmt.Fprintln(w, "No models are loaded in memory.")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSIZE\tPROCESSOR\tUNTIL")
+	for _, m := range running {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", m.Name, formatBytes(m.Size), formatProcessor(m.Size, m.SizeVRAM), formatTimestamp(m.ExpiresAt))
+	}
+	return tw.Flush()
+}
+
+// showModel prints the details of a model.
+func showModel(cli *client.HTTPClient, w io.Writer, name string) error {
+	info, err := cli.ShowModel(name)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(tw, "Model:\t%s\n", name)
+	fmt.Fprintf(tw, "Family:\t%s\n", info.Details.Family)
+	fmt.Fprintf(tw, "Parameters:\t%s\n", info.Details.ParameterSize)
+	fmt.Fprintf(tw, "Quantization:\t%s\n", info.Details.QuantizationLevel)
+	fmt.Fprintf(tw, "Format:\t%s\n", info.Details.Format)
+	for key, value := range info.ModelInfo {
+		if strings.HasSuffix(key, ".context_length") {
+			fmt.Fprintf(tw, "Context length:\t%v\n", value)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if info.Parameters != "" {
+		fmt.Fprintf(w, "\nParameters:\n%s\n", indent(info.Parameters))
+	}
+	if info.Template != "" {
+		fmt.Fprintf(w, "\nTemplate:\n%s\n", indent(info.Template))
+	}
+	return nil
+}
+
+// pullModel downloads a model, rendering the streamed progress on a single line per status.
+func pullModel(cli *client.HTTPClient, w io.Writer, name string) error {
+	lastStatus := ""
+	err := cli.PullModel(name, func(p models.PullProgress) {
+		status := p.Status
+		if p.Digest != "" {
+			status = fmt.Sprintf("%s %s", p.Status, shortDigest(p.Digest))
+		}
+		if status != lastStatus && lastStatus != "" {
+			fmt.Fprintln(w)
+		}
+		lastStatus = status
+
+		if p.Total > 0 {
+			fmt.Fprintf(w, "\r\033[K%s %3d%% (%s/%s)", status, p.Completed*100/p.Total, formatBytes(p.Completed), formatBytes(p.Total))
+		} else {
+			fmt.Fprintf(w, "\r\033[K%s", status)
+		}
+	})
+	if lastStatus != "" {
+		fmt.Fprintln(w)
+	}
+	return err
+}
+
+// formatBytes renders a size in bytes using decimal units, like the ollama CLI.
+func formatBytes(size int64) string {
+	const unit = 1000
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value, exp := float64(size)/unit, 0
+	for value >= unit && exp < 4 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", value, "KMGTP"[exp])
+}
+
+// formatProcessor describes how a loaded model is split between CPU and GPU memory.
+func formatProcessor(size, sizeVRAM int64) string {
+	switch {
+	case size == 0 || sizeVRAM == 0:
+		return "100% CPU"
+	case sizeVRAM >= size:
+		return "100% GPU"
+	default:
+		gpu := sizeVRAM * 100 / size
+		return fmt.Sprintf("%d%%/%d%% CPU/GPU", 100-gpu, gpu)
+	}
+}
+
+// formatTimestamp renders an RFC 3339 timestamp in local time, or returns it unchanged.
+func formatTimestamp(value string) string {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return value
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// shortDigest shortens a digest such as sha256:abcdef... for display.
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
+
+// indent indents every line of the text.
+func indent(text string) string {
+	return "    " + strings.ReplaceAll(strings.TrimRight(text, "\n"), "\n", "\n    ")
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lucianoayres/nino-cli/internal/config"
+	"github.com/lucianoayres/nino-cli/internal/logger"
+)
+
+const profileUsage = `Usage: nino profile <command> [arguments]
+
+Commands:
+  list      List the profiles of the configuration files (* marks the selected one)
+  use NAME  Select a profile by default, in the user configuration file
+
+Profiles are defined in the [profiles.NAME] tables of the configuration files.
+A profile is selected with the -profile flag, the NINO_PROFILE environment
+variable or the profile setting, and takes precedence over the environment and
+the configuration files. Flags still take precedence over the profile.
+`
+
+// runProfileCommand runs the "nino profile" subcommands and returns the exit code.
+func runProfileCommand(args []string) int {
+	logger.GetLogger(false)
+
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, profileUsage)
+		return 1
+	}
+
+	var err error
+	switch command, rest := args[0], args[1:]; {
+	case command == "list" && len(rest) == 0:
+		err = listProfiles()
+	case command == "use" && len(rest) == 1:
+		var path string
+		if path, err = config.SelectProfile(rest[0]); err == nil {
+			fmt.Printf("Using profile %s (saved to %s)\n", rest[0], path)
+		}
+	case command == "help" || command == "-h" || command == "-help":
+		fmt.Print(profileUsage)
+	default:
+		fmt.Fprint(os.Stderr, profileUsage)
+		return 1
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// listProfiles prints the profiles with their server, model and sources.
+func listProfiles() error {
+	profiles, selected, err := config.Profiles()
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		fmt.Println("No profiles. Add a [profiles.NAME] table to a configuration file (see 'nino config path').")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "\tNAME\tURL\tMODEL\tSOURCE")
+	for _, profile := range profiles {
+		mark := ""
+		if profile.Name == selected {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", mark, profile.Name,
+			profileValue(profile, "url"), profileValue(profile, "model"), strings.Join(profile.Sources, ", "))
+	}
+	return w.Flush()
+}
+
+// profileValue returns the value of a setting of the profile, or "-" when it doesn't set it.
+func profileValue(profile config.Profile, name string) string {
+	values := profile.Values[name]
+	if len(values) == 0 {
+		return "-"
+	}
+	return values[len(values)-1]
+}
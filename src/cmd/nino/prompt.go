@@ -0,0 +1,195 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lucianoayres/nino-cli/internal/library"
+	"github.com/lucianoayres/nino-cli/internal/logger"
+)
+
+const promptUsage = `Usage: nino prompt <command> [arguments]
+
+Commands:
+  list                 List the prompts of the library
+  show NAME            Print a prompt
+  edit [-project] NAME Edit a prompt with $EDITOR, creating it if needed
+                       (in the project library with -project)
+  run NAME [flags]     Run a prompt, like -prompt-file with the same flags
+
+Prompts are read from .nino/prompts at the root of the project (or in the
+working directory outside of a project), then from
+$XDG_CONFIG_HOME/nino/prompts (or ~/.config/nino/prompts).
+`
+
+// newPromptTemplate is the content of the prompts created by "nino prompt edit"
+const newPromptTemplate = `---
+description:
+---
+`
+
+// runPromptCommand runs the "nino prompt" subcommands and returns the exit code.
+func runPromptCommand(args []string) int {
+	// Running a prompt is the same as passing its file with -prompt-file
+	if len(args) > 0 && args[0] == "run" {
+		runArgs, err := promptRunArgs(args[1:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return runRunCommand("prompt run", promptUsage+"\nFlags of run:\n", runArgs)
+	}
+
+	logger.GetLogger(false)
+
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, promptUsage)
+		return 1
+	}
+
+	var err error
+	switch command, rest := args[0], args[1:]; {
+	case command == "list" && len(rest) == 0:
+		err = listPrompts()
+	case command == "show" && len(rest) == 1:
+		err = showPrompt(rest[0])
+	case command == "edit" && len(rest) > 0:
+		fs := flag.NewFlagSet("edit", flag.ContinueOnError)
+		project := fs.Bool("project", false, "Create the prompt in the project library")
+		if err := fs.Parse(rest); err != nil || fs.NArg() != 1 {
+			fmt.Fprint(os.Stderr, promptUsage)
+			return 1
+		}
+		err = editPrompt(fs.Arg(0), *project)
+	case command == "help" || command == "-h" || command == "-help":
+		fmt.Print(promptUsage)
+	default:
+		fmt.Fprint(os.Stderr, promptUsage)
+		return 1
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// promptRunArgs returns the command-line arguments that run the named prompt
+// as a prompt file, followed by the given flags.
+func promptRunArgs(args []string) ([]string, error) {
+	logger.GetLogger(false)
+
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return nil, errors.New("usage: nino prompt run NAME [flags]")
+	}
+	prompt, err := library.Find(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{"-prompt-file", prompt.Path}, args[1:]...), nil
+}
+
+// listPrompts prints the prompts of the library.
+func listPrompts() error {
+	prompts, err := library.List()
+	if err != nil {
+		return err
+	}
+	if len(prompts) == 0 {
+		fmt.Println("No prompts found. Create one with: nino prompt edit NAME")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSCOPE\tDESCRIPTION")
+	for _, p := range prompts {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", p.Name, p.Scope, p.Description)
+	}
+	return tw.Flush()
+}
+
+// showPrompt prints the content of a prompt.
+func showPrompt(name string) error {
+	prompt, err := library.Find(name)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(prompt.Path)
+	if err != nil {
+		return fmt.Errorf("error reading prompt file '%s': %v", prompt.Path, err)
+	}
+	fmt.Print(string(content))
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		fmt.Println()
+	}
+	return nil
+}
+
+// editPrompt opens a prompt in the user's editor, creating it in the user or
+// project library when it doesn't exist.
+func editPrompt(name string, project bool) error {
+	scope := library.ScopeUser
+	if project {
+		scope = library.ScopeProject
+	}
+
+	var path string
+	if prompt, err := library.Find(name); err == nil && (prompt.Scope == scope || !project) {
+		path = prompt.Path
+	} else {
+		if path, err = library.NewPath(name, scope); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("error creating prompt directory: %v", err)
+		}
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil && !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("error creating prompt file: %v", err)
+		}
+		if err == nil {
+			_, err = file.WriteString(newPromptTemplate)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("error creating prompt file: %v", err)
+			}
+			fmt.Fprintf(os.Stderr, "Created %s\n", path)
+		}
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	// Like git, let the shell split the editor command, so it can hold quoted arguments
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		fields := strings.Fields(editor)
+		cmd = exec.Command(fields[0], append(fields[1:], path)...)
+	} else {
+		cmd = exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running editor '%s': %v", editor, err)
+	}
+	return nil
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/lucianoayres/nino-cli/internal/client"
+	"github.com/lucianoayres/nino-cli/internal/config"
+	"github.com/lucianoayres/nino-cli/internal/contextmanager"
+	"github.com/lucianoayres/nino-cli/internal/files"
+	"github.com/lucianoayres/nino-cli/internal/logger"
+	"github.com/lucianoayres/nino-cli/internal/models"
+	"github.com/lucianoayres/nino-cli/internal/processor"
+	"github.com/lucianoayres/nino-cli/internal/schema"
+	"github.com/lucianoayres/nino-cli/internal/session"
+	"github.com/lucianoayres/nino-cli/internal/utils"
+)
+
+const runUsage = `Usage: nino run [flags] [prompt]
+       nino [flags] [prompt]
+
+Sends the prompt to the model and streams its response. The prompt is the rest
+of the arguments, unless -prompt or -prompt-file is given, followed by the
+input piped to stdin and the attached files.
+
+Flags:
+`
+
+const chatUsage = `Usage: nino chat [flags] [prompt]
+
+Without a prompt, starts an interactive chat with the model (type /help in the
+chat for its commands). With a prompt, or input piped to stdin, sends it as a
+chat message to the /api/chat endpoint, like 'nino run -chat'. Continue a
+stored conversation with -session NAME.
+
+Flags:
+`
+
+// runRunCommand runs a prompt with the flags of the "nino run" command, printing
+// the given usage for -h, and returns the exit code.
+func runRunCommand(name, usage string, args []string) int {
+	// Parse command-line arguments using the config package
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	cfg, err := config.ParseArgs(fs, args)
+	var flagErr *config.FlagError
+	if errors.As(err, &flagErr) {
+		return exitCodeForFlagError(flagErr.Err)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing arguments: %v\n", err)
+		return 1
+	}
+
+	// Initialize the logger
+	log := logger.GetLogger(cfg.Verbose)
+
+	log.StartTimer("Total Execution Time")
+	defer log.StopTimer("Total Execution Time")
+
+	log.Info("Starting NINO CLI tool")
+
+	if cfg.Profile != "" {
+		log.Info("Using profile %s", cfg.Profile)
+	}
+
+	// Store the context and session with the project when scoped to it
+	utils.SetDataScope(cfg.DataScope)
+	if cfg.DataScope != "" {
+		log.Info("Context and sessions are scoped to project %s", cfg.DataScope)
+	}
+
+	for _, ref := range cfg.References {
+		if !ref.Resolved {
+			log.Info("Reference %s does not match a file, left as written", ref.Token)
+		} else if ref.Start > 0 {
+			log.Info("Expanded reference %s to lines %d-%d of %s", ref.Token, ref.Start, ref.End, ref.Path)
+		} else {
+			log.Info("Expanded reference %s to the contents of %s", ref.Token, ref.Path)
+		}
+	}
+
+	// Check if Ollama server is running
+	log.StartTimer("Check Ollama Server")
+	log.Info("Checking if Ollama server is running at %s", cfg.URL)
+	if !utils.IsOllamaRunning(cfg.URL, cfg.Headers) {
+		fmt.Printf("Oops! It looks like the Ollama server isn't running at %s.\n", cfg.URL)
+		fmt.Println("Please start the server at this URL or set the correct URL with NINO_URL or the url setting (see 'nino config show').")
+		fmt.Println("To start the server, you can run:")
+		fmt.Printf("ollama serve & ollama run %s\n", cfg.Model)
+		return 1
+	}
+	log.Info("Ollama server is running")
+	log.StopTimer("Check Ollama Server")
+
+	// Initialize the HTTP client
+	log.StartTimer("Initialize HTTP Client")
+	log.Info("Initializing HTTP client with base URL: %s", cfg.URL)
+	cli := client.NewHTTPClient(cfg.URL)
+	cli.Headers = cfg.Headers
+	log.StopTimer("Initialize HTTP Client")
+
+	// Read and encode images
+	var imagesBase64 []string
+	if len(cfg.ImagePaths) > 0 {
+		log.StartTimer("Process Images")
+		log.Info("Reading and encoding %d image(s)", len(cfg.ImagePaths))
+		imagesBase64, err = utils.ReadImagesAsBase64(cfg.ImagePaths)
+		if err != nil {
+			log.Error("Error processing images: %v", err)
+			return 1
+		}
+		log.Info("Images processed successfully")
+		log.StopTimer("Process Images")
+	} else {
+		log.Info("No images provided")
+	}
+
+	// Attach the files to the prompt
+	if len(cfg.FilePaths) > 0 {
+		log.StartTimer("Attach Files")
+		attachments, err := files.Collect(cfg.FilePaths, cfg.FileBudget)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error attaching files: %v\n", err)
+			return 1
+		}
+		if !cfg.Silent {
+			attachments.Report(os.Stderr)
+		}
+		if len(attachments.Included) == 0 && strings.TrimSpace(cfg.Prompt) == "" {
+			fmt.Fprintln(os.Stderr, "Error: none of the files could be attached and no prompt was given")
+			return 1
+		}
+		if attachments.Text != "" {
+			cfg.Prompt = strings.TrimSpace(cfg.Prompt + "\n\n" + attachments.Text)
+		}
+		log.StopTimer("Attach Files")
+	}
+
+	// The interactive chat sends its own requests
+	if cfg.Interactive {
+		return runChat(cfg, cli, log, imagesBase64)
+	}
+
+	// Prepare the request payload
+	log.StartTimer("Prepare Request Payload")
+	log.Info("Preparing request payload")
+	var format json.RawMessage
+	if cfg.Schema != nil {
+		format, _ = json.Marshal(cfg.Schema)
+	} else if cfg.Format != "" {
+		format, _ = json.Marshal(cfg.Format)
+	}
+	payload := models.RequestPayload{
+		Model:      cfg.Model,
+		Prompt:     cfg.Prompt,
+		System:     cfg.System,
+		Images:     imagesBase64, // Assign the base64-encoded images
+		Format:     format,
+		Stream:     cfg.Stream,
+		Keep_Alive: cfg.Keep_Alive,
+		Options:    cfg.Options,
+	}
+
+	// In chat mode the prompt is sent as a user message to the chat endpoint,
+	// after the history of the session if one is used
+	userMessage := models.Message{Role: "user", Content: cfg.Prompt, Images: imagesBase64}
+	var history []models.Message
+	if cfg.Session != "" {
+		history, err = session.Load(cfg.Session)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading session: %v\n", err)
+			return 1
+		}
+		log.Info("Replaying %d message(s) from session %s", len(history), cfg.Session)
+	}
+	// The system prompt is sent as the first message and never stored in the session
+	var messages []models.Message
+	if cfg.System != "" {
+		messages = append(messages, models.Message{Role: "system", Content: cfg.System})
+	}
+	messages = append(append(messages, history...), userMessage)
+	chatPayload := models.ChatRequestPayload{
+		Model:      cfg.Model,
+		Messages:   messages,
+		Format:     format,
+		Stream:     cfg.Stream,
+		Keep_Alive: cfg.Keep_Alive,
+		Options:    cfg.Options,
+	}
+	log.StopTimer("Prepare Request Payload")
+
+	// Load the context of the previous request for the model, unless disabled.
+	// Chat mode carries the conversation in the messages instead.
+	if !cfg.DisableContext && !cfg.Chat {
+		log.StartTimer("Load Context Data")
+		contextData, err := contextmanager.LoadContext(cfg.Model)
+		if err != nil {
+			log.Error("Error loading context data: %v", err)
+			return 1
+		}
+		if len(contextData) > 0 {
+			log.Info("Including %d context tokens in the request", len(contextData))
+			payload.Context = contextData
+		}
+		log.StopTimer("Load Context Data")
+	}
+
+	// Define context handler
+	contextHandler := func(context []int) error {
+		log.StartTimer("Save Context Data")
+		log.Info("Saving context data")
+		err := contextmanager.SaveContext(cfg.Model, context)
+		if err != nil {
+			log.Error("Failed to save context data: %v", err)
+			log.StopTimer("Save Context Data")
+			return err
+		}
+		log.StopTimer("Save Context Data")
+		return nil
+	}
+
+	// Cancel the request on Ctrl-C or SIGTERM, keeping the output written so far. With
+	// SIGPIPE ignored, writing to a closed stdout fails with EPIPE instead of killing
+	// nino, so the generation stops as soon as the reader goes away.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	signal.Ignore(syscall.SIGPIPE)
+
+	gen := &generator{
+		ctx:            ctx,
+		cfg:            cfg,
+		cli:            cli,
+		log:            log,
+		payload:        payload,
+		chatPayload:    chatPayload,
+		contextHandler: contextHandler,
+	}
+
+	response, err := gen.send()
+	if err != nil && ctx.Err() != nil {
+		return interrupted(cfg, nil, false)
+	}
+	if err != nil {
+		log.Error("Error sending request: %v", err)
+		return 1
+	}
+	defer response.Body.Close()
+
+	// Prepare writers
+	var writers []io.Writer
+	if !cfg.Silent {
+		writers = append(writers, os.Stdout) // Write to console unless in silent mode
+	}
+
+	// If Output is specified, add the file to writers
+	var outputFile *os.File
+	if cfg.Output != "" {
+		log.StartTimer("Prepare Output File")
+		log.Info("Output will be saved to file: %s", cfg.Output)
+		// Validate the output directory exists
+		dir := filepath.Dir(cfg.Output)
+		if dir != "." { // Skip if current directory
+			if _, err := os.Stat(dir); os.IsNotExist(err) {
+				log.Error("Error: Directory '%s' does not exist.", dir)
+				return 1
+			}
+		}
+
+		file, err := os.Create(cfg.Output)
+		if err != nil {
+			log.Error("Error creating output file '%s': %v", cfg.Output, err)
+			return 1
+		}
+		defer file.Close()
+		outputFile = file
+		writers = append(writers, file)
+		log.Info("Output file created successfully")
+		log.StopTimer("Prepare Output File")
+	}
+
+	// Keep a copy of the output to validate it against the JSON schema
+	var output bytes.Buffer
+	if cfg.Schema != nil && cfg.MaxAttempts <= 1 {
+		writers = append(writers, &output)
+	}
+
+	// Create a MultiWriter to write to all destinations
+	multiWriter := io.MultiWriter(writers...)
+
+	// Clear the line before writing the response if not in silent mode
+	if !cfg.Silent {
+		fmt.Print("\r\033[K")
+	}
+
+	// Process the response and write to all writers
+	log.StartTimer("Process Response")
+	log.Info("Processing response")
+	var assistantMessage models.Message
+	if cfg.MaxAttempts > 1 {
+		assistantMessage, err = gen.processValidated(response, multiWriter)
+	} else {
+		assistantMessage, err = gen.process(response.Body, multiWriter)
+		if err == nil && cfg.Schema != nil {
+			err = processor.ValidateOutput(output.Bytes(), cfg.Schema)
+		}
+	}
+	if err != nil && (ctx.Err() != nil || errors.Is(err, syscall.EPIPE)) {
+		log.Info("Stopped processing the response: %v", err)
+		return interrupted(cfg, outputFile, ctx.Err() == nil)
+	}
+	var validationErr *schema.ValidationError
+	if errors.As(err, &validationErr) {
+		if !cfg.Silent {
+			fmt.Fprintln(os.Stdout)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitInvalidOutput
+	}
+	if err != nil {
+		log.Error("Error processing response: %v", err)
+		return 1
+	}
+	log.Info("Response processed successfully")
+	log.StopTimer("Process Response")
+
+	// Store the exchange in the session
+	if cfg.Session != "" {
+		log.StartTimer("Save Session")
+		if err := session.Append(cfg.Session, userMessage, assistantMessage); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving session: %v\n", err)
+			return 1
+		}
+		log.StopTimer("Save Session")
+	}
+
+	// If output was saved to a file and not in silent mode, notify the user
+	if cfg.Output != "" && !cfg.Silent {
+		fmt.Printf("\nOutput saved to %s\n", cfg.Output)
+		log.Info("Output saved to file")
+	} else if !cfg.Silent {
+		// Add a newline for console output, so the shell prompt is displayed below
+		fmt.Fprintln(os.Stdout)
+	}
+	log.Info("NINO CLI tool completed successfully")
+	return 0
+}
+
+// interrupted ends a run cut short by a signal, or by the reader of stdout going away
+// when brokenPipe is set. The output file, if any, keeps the output written so far and
+// is marked as incomplete with -mark-incomplete. It returns the exit code.
+func interrupted(cfg *config.Config, outputFile *os.File, brokenPipe bool) int {
+	reason := "interrupted"
+	if brokenPipe {
+		reason = "the output pipe was closed"
+	}
+	if outputFile != nil && cfg.MarkIncomplete {
+		if _, err := fmt.Fprintf(outputFile, "\n\n[incomplete output: %s]\n", reason); err != nil {
+			fmt.Fprintf(os.Stderr, "Error marking the output file as incomplete: %v\n", err)
+		}
+	}
+
+	// Nobody reads stdout anymore, so there is nothing to report
+	if brokenPipe {
+		return exitBrokenPipe
+	}
+	if !cfg.Silent {
+		// End the partial response, so the message is displayed below it
+		fmt.Fprintln(os.Stdout)
+	}
+	if outputFile != nil {
+		fmt.Fprintf(os.Stderr, "Interrupted. The partial output was saved to %s\n", cfg.Output)
+	} else {
+		fmt.Fprintln(os.Stderr, "Interrupted.")
+	}
+	return exitInterrupted
+}
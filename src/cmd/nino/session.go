@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lucianoayres/nino-cli/internal/config"
+	"github.com/lucianoayres/nino-cli/internal/logger"
+	"github.com/lucianoayres/nino-cli/internal/session"
+	"github.com/lucianoayres/nino-cli/internal/utils"
+)
+
+const sessionUsage = `Usage: nino session <command> [arguments]
+
+Commands:
+  list                 List the stored chat sessions
+  show NAME            Print the message history of a session
+  rm NAME              Delete a session
+  rename OLD NEW       Rename a session
+
+With scope = "project" in the configuration, the sessions of the project of
+the working directory are used.
+`
+
+// runSessionCommand runs the "nino session" subcommands and returns the exit code.
+func runSessionCommand(args []string) int {
+	logger.GetLogger(false)
+	utils.SetDataScope(config.DefaultDataScope())
+
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, sessionUsage)
+		return 1
+	}
+
+	var err error
+	switch command, rest := args[0], args[1:]; {
+	case command == "list" && len(rest) == 0:
+		err = listSessions()
+	case command == "show" && len(rest) == 1:
+		err = showSession(rest[0])
+	case command == "rm" && len(rest) == 1:
+		err = session.Remove(rest[0])
+	case command == "rename" && len(rest) == 2:
+		err = session.Rename(rest[0], rest[1])
+	case command == "help" || command == "-h" || command == "-help":
+		fmt.Print(sessionUsage)
+	default:
+		fmt.Fprint(os.Stderr, sessionUsage)
+		return 1
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// listSessions prints the stored sessions with their size and last update time.
+func listSessions() error {
+	sessions, err := session.List()
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		fmt.Println("No sessions found. Start one with: nino -session NAME \"prompt\"")
+		return nil
+	}
+	for _, s := range sessions {
+		fmt.Printf("%-24s %4d messages   updated %s\n", s.Name, s.Messages, s.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// showSession prints every message of the given session.
+func showSession(name string) error {
+	messages, err := session.Load(name)
+	if err != nil {
+		return err
+	}
+	if messages == nil {
+		return fmt.Errorf("session '%s' does not exist", name)
+	}
+	for i, message := range messages {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("[%s]\n%s\n", message.Role, message.Content)
+		if len(message.Images) > 0 {
+			fmt.Printf("(%d image(s) attached)\n", len(message.Images))
+		}
+	}
+	return nil
+}
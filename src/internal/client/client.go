@@ -2,9 +2,12 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/lucianoayres/nino-cli/internal/logger"
 	"github.com/lucianoayres/nino-cli/internal/models"
@@ -14,6 +17,8 @@ import (
 type HTTPClient struct {
 	BaseURL    string
 	HTTPClient *http.Client
+	Headers    map[string]string // Additional headers sent with every request
+	Timeouts   Timeouts          // The limits of the requests, none by default
 	log        *logger.Logger
 }
 
@@ -30,37 +35,93 @@ func NewHTTPClient(baseURL string) *HTTPClient {
 	}
 }
 
+// Endpoint returns the URL of the given API path (e.g. "/api/chat") on the same
+// server as BaseURL. Any path prefix in front of "/api/" is preserved, so servers
+// behind a reverse proxy keep working.
+func (c *HTTPClient) Endpoint(path string) string {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		c.log.Error("URL parsing error: %v", err)
+		return c.BaseURL
+	}
+	prefix := strings.TrimSuffix(u.Path, "/")
+	if idx := strings.LastIndex(u.Path, "/api/"); idx >= 0 {
+		prefix = u.Path[:idx]
+	}
+	u.Path = prefix + path
+	u.RawPath = ""
+	return u.String()
+}
+
 // SendRequest sends a POST request with the given payload and returns the HTTP response.
 func (c *HTTPClient) SendRequest(payload models.RequestPayload) (*http.Response, error) {
-	c.log.Info("Marshaling request payload to JSON")
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		c.log.Error("JSON marshaling error: %v", err)
-		return nil, err
+	return c.SendRequestContext(context.Background(), payload)
+}
+
+// SendRequestContext is like SendRequest, but canceling the context aborts the
+// request, including the reading of the response body.
+func (c *HTTPClient) SendRequestContext(ctx context.Context, payload models.RequestPayload) (*http.Response, error) {
+	return c.do(ctx, "POST", c.BaseURL, payload)
+}
+
+// SendChatRequest sends a POST request with the given chat payload to the chat
+// endpoint and returns the HTTP response.
+func (c *HTTPClient) SendChatRequest(payload models.ChatRequestPayload) (*http.Response, error) {
+	return c.SendChatRequestContext(context.Background(), payload)
+}
+
+// SendChatRequestContext is like SendChatRequest, but canceling the context aborts
+// the request, including the reading of the response body.
+func (c *HTTPClient) SendChatRequestContext(ctx context.Context, payload models.ChatRequestPayload) (*http.Response, error) {
+	return c.do(ctx, "POST", c.Endpoint("/api/chat"), payload)
+}
+
+// do sends a request with the payload marshaled as JSON to the given URL.
+// A nil payload sends a request without a body. A request exceeding one of the
+// timeouts of the client fails with a TimeoutError, even while its body is read.
+func (c *HTTPClient) do(ctx context.Context, method, url string, payload interface{}) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		c.log.Info("Marshaling request payload to JSON")
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			c.log.Error("JSON marshaling error: %v", err)
+			return nil, err
+		}
+		c.log.Info("JSON payload marshaled successfully")
+
+		// Log the request payload
+		c.log.Info("Request payload: %s", string(jsonData))
+		body = bytes.NewBuffer(jsonData)
 	}
-	c.log.Info("JSON payload marshaled successfully")
-	
-	// Log the request payload
-	c.log.Info("Request payload: %s", string(jsonData))
 
-	c.log.Info("Creating new HTTP POST request to %s", c.BaseURL)
-	req, err := http.NewRequest("POST", c.BaseURL, bytes.NewBuffer(jsonData))
+	c.log.Info("Creating new HTTP %s request to %s", method, url)
+	ctx, watchdog := startWatchdog(ctx, c.Timeouts)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
+		watchdog.stop()
 		c.log.Error("HTTP request creation error: %v", err)
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	c.log.Info("HTTP request headers set: Content-Type=application/json")
+	for name, value := range c.Headers {
+		req.Header.Set(name, value)
+		// Only log the names, the values may be credentials
+		c.log.Info("HTTP request header set: %s", name)
+	}
 
 	// Send the request
 	c.log.Info("Sending HTTP request")
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
+		watchdog.stop()
 		c.log.Error("HTTP request error: %v", err)
-		return nil, err
+		return nil, timeoutCause(ctx, err)
 	}
 
 	c.log.Info("Received HTTP response with status code: %d", resp.StatusCode)
+	resp.Body = &watchedBody{ReadCloser: resp.Body, ctx: ctx, w: watchdog}
 	return resp, nil
 }
@@ -2,12 +2,16 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/lucianoayres/nino-cli/internal/logger"
 	"github.com/lucianoayres/nino-cli/internal/models"
@@ -343,3 +347,149 @@ func TestHTTPClient_SendRequest_NetworkError(t *testing.T) {
 		t.Errorf("Expected response to be nil, got %v", resp)
 	}
 }
+
+// TestHTTPClient_Endpoint tests that API paths are derived from the configured base URL.
+func TestHTTPClient_Endpoint(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	tests := []struct {
+		baseURL string
+		path    string
+		want    string
+	}{
+		{"http://localhost:11434/api/generate", "/api/chat", "http://localhost:11434/api/chat"},
+		{"http://localhost:11434", "/api/chat", "http://localhost:11434/api/chat"},
+		{"http://localhost:11434/", "/api/tags", "http://localhost:11434/api/tags"},
+		{"https://proxy.example.com/ollama/api/generate", "/api/chat", "https://proxy.example.com/ollama/api/chat"},
+		{"http://[::1]:NamedPort", "/api/chat", "http://[::1]:NamedPort"}, // Unparseable URLs are returned as-is
+	}
+
+	for _, tt := range tests {
+		client := &HTTPClient{BaseURL: tt.baseURL, log: logger.GetLogger(true)}
+		if got := client.Endpoint(tt.path); got != tt.want {
+			t.Errorf("Endpoint(%q) with BaseURL %q = %q; want %q", tt.path, tt.baseURL, got, tt.want)
+		}
+	}
+}
+
+// TestHTTPClient_SendChatRequest tests that chat requests are posted to the chat endpoint.
+func TestHTTPClient_SendChatRequest(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	var gotURL string
+	var gotPayload models.ChatRequestPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&gotPayload); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		fmt.Fprint(w, `{"message": {"role": "assistant", "content": "Hi"}, "done": true}`)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL + "/api/generate")
+	payload := models.ChatRequestPayload{
+		Model:    "llama3.2",
+		Messages: []models.Message{{Role: "user", Content: "Hello"}},
+	}
+
+	resp, err := client.SendChatRequest(payload)
+	if err != nil {
+		t.Fatalf("SendChatRequest() unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if gotURL != "/api/chat" {
+		t.Errorf("Expected request to /api/chat, got %s", gotURL)
+	}
+	if len(gotPayload.Messages) != 1 || gotPayload.Messages[0].Content != "Hello" {
+		t.Errorf("Unexpected messages sent: %+v", gotPayload.Messages)
+	}
+}
+
+// TestHTTPClient_Headers tests that the additional headers are sent with the requests.
+func TestHTTPClient_Headers(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	var gotHeader http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		fmt.Fprint(w, `{"response": "Hi", "done": true}`)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL + "/api/generate")
+	client.Headers = map[string]string{"Authorization": "Bearer secret", "X-Team": "docs"}
+
+	resp, err := client.SendRequest(models.RequestPayload{Model: "llama3.2", Prompt: "Hello"})
+	if err != nil {
+		t.Fatalf("SendRequest() unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if got := gotHeader.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization header = %q, want %q", got, "Bearer secret")
+	}
+	if got := gotHeader.Get("X-Team"); got != "docs" {
+		t.Errorf("X-Team header = %q, want %q", got, "docs")
+	}
+	if got := gotHeader.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type header = %q, want application/json", got)
+	}
+}
+
+// TestHTTPClient_SendChatRequestContext tests that canceling the context aborts the request.
+func TestHTTPClient_SendChatRequestContext(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	client := NewHTTPClient(server.URL + "/api/generate")
+	_, err := client.SendChatRequestContext(ctx, models.ChatRequestPayload{Model: "llama3.2"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("SendChatRequestContext() error = %v, want context.Canceled", err)
+	}
+}
+
+// TestHTTPClient_SendRequestContext tests that canceling the context aborts the reading
+// of a response that is being streamed.
+func TestHTTPClient_SendRequestContext(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response": "Hello", "done": false}` + "\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := NewHTTPClient(server.URL + "/api/generate")
+	response, err := client.SendRequestContext(ctx, models.RequestPayload{Model: "llama3.2", Prompt: "Hi"})
+	if err != nil {
+		t.Fatalf("SendRequestContext() error = %v", err)
+	}
+	defer response.Body.Close()
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = io.ReadAll(response.Body)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("reading the response body: error = %v, want context.Canceled", err)
+	}
+}
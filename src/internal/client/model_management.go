@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/lucianoayres/nino-cli/internal/models"
+)
+
+// Version returns the version of the Ollama server. Servers that answer with
+// anything other than a version, such as a proxy error page, are reported as errors.
+func (c *HTTPClient) Version() (string, error) {
+	var response models.VersionResponse
+	if err := c.getJSON("/api/version", &response); err != nil {
+		return "", err
+	}
+	if response.Version == "" {
+		return "", fmt.Errorf("the server at %s did not report an Ollama version", c.Endpoint("/api/version"))
+	}
+	return response.Version, nil
+}
+
+// ListModels returns the models available on the server.
+func (c *HTTPClient) ListModels() ([]models.ModelInfo, error) {
+	var response models.ListModelsResponse
+	if err := c.getJSON("/api/tags", &response); err != nil {
+		return nil, err
+	}
+	return response.Models, nil
+}
+
+// ListRunningModels returns the models currently loaded in memory.
+func (c *HTTPClient) ListRunningModels() ([]models.RunningModel, error) {
+	var response models.ListRunningModelsResponse
+	if err := c.getJSON("/api/ps", &response); err != nil {
+		return nil, err
+	}
+	return response.Models, nil
+}
+
+// ShowModel returns the details of the given model.
+func (c *HTTPClient) ShowModel(name string) (*models.ShowModelResponse, error) {
+	resp, err := c.do(context.Background(), "POST", c.Endpoint("/api/show"), models.ModelRequest{Model: name})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return nil, err
+	}
+
+	var response models.ShowModelResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		c.log.Error("JSON decoding error: %v", err)
+		return nil, fmt.Errorf("failed to decode JSON response: %v", err)
+	}
+	return &response, nil
+}
+
+// PullModel downloads the given model, calling progress for each status update
+// streamed by the server.
+func (c *HTTPClient) PullModel(name string, progress func(models.PullProgress)) error {
+	stream := true
+	resp, err := c.do(context.Background(), "POST", c.Endpoint("/api/pull"), models.ModelRequest{Model: name, Stream: &stream})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var update models.PullProgress
+		if err := decoder.Decode(&update); err == io.EOF {
+			return nil
+		} else if err != nil {
+			c.log.Error("JSON decoding error: %v", err)
+			return fmt.Errorf("failed to decode JSON response: %v", err)
+		}
+		if update.Error != "" {
+			return fmt.Errorf("failed to pull model '%s': %s", name, update.Error)
+		}
+		if progress != nil {
+			progress(update)
+		}
+	}
+}
+
+// DeleteModel removes the given model from the server.
+func (c *HTTPClient) DeleteModel(name string) error {
+	resp, err := c.do(context.Background(), "DELETE", c.Endpoint("/api/delete"), models.ModelRequest{Model: name})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkStatus(resp)
+}
+
+// getJSON sends a GET request to the given API path and decodes the JSON response into out.
+func (c *HTTPClient) getJSON(path string, out interface{}) error {
+	resp, err := c.do(context.Background(), "GET", c.Endpoint(path), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		c.log.Error("JSON decoding error: %v", err)
+		return fmt.Errorf("failed to decode JSON response: %v", err)
+	}
+	return nil
+}
+
+// checkStatus returns an error for non-OK responses, using the error message of the
+// response body when there is one.
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var payload struct {
+		Error string `json:"error"`
+	}
+	message := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &payload) == nil && payload.Error != "" {
+		message = payload.Error
+	}
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+	return fmt.Errorf("received HTTP status %d: %s", resp.StatusCode, message)
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lucianoayres/nino-cli/internal/logger"
+	"github.com/lucianoayres/nino-cli/internal/models"
+)
+
+// newModelServer starts a test server emulating the model management endpoints.
+func newModelServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/version", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"version": "0.5.1"}`)
+	})
+	mux.HandleFunc("GET /api/tags", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"models": [{"name": "llama3.2:latest", "size": 2019393189, "details": {"parameter_size": "3.2B"}}]}`)
+	})
+	mux.HandleFunc("GET /api/ps", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"models": [{"name": "llama3.2:latest", "size": 100, "size_vram": 50}]}`)
+	})
+	mux.HandleFunc("POST /api/show", func(w http.ResponseWriter, r *http.Request) {
+		var request models.ModelRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		if request.Model != "llama3.2" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"error": "model '%s' not found"}`, request.Model)
+			return
+		}
+		fmt.Fprint(w, `{"template": "{{ .Prompt }}", "details": {"family": "llama"}, "model_info": {"llama.context_length": 131072}}`)
+	})
+	mux.HandleFunc("POST /api/pull", func(w http.ResponseWriter, r *http.Request) {
+		var request models.ModelRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		if request.Model == "broken" {
+			fmt.Fprint(w, `{"status": "pulling manifest"}`+"\n"+`{"error": "pull model manifest: file does not exist"}`+"\n")
+			return
+		}
+		fmt.Fprint(w, `{"status": "pulling manifest"}`+"\n"+`{"status": "downloading", "digest": "sha256:abc", "total": 10, "completed": 10}`+"\n"+`{"status": "success"}`+"\n")
+	})
+	mux.HandleFunc("DELETE /api/delete", func(w http.ResponseWriter, r *http.Request) {
+		var request models.ModelRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		if request.Model != "llama3.2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestModelManagement tests the model management methods of the HTTPClient.
+func TestModelManagement(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	server := newModelServer(t)
+	client := NewHTTPClient(server.URL + "/api/generate")
+
+	t.Run("Version", func(t *testing.T) {
+		version, err := client.Version()
+		if err != nil || version != "0.5.1" {
+			t.Errorf("Version() = %q, %v; want \"0.5.1\", nil", version, err)
+		}
+	})
+
+	t.Run("ListModels", func(t *testing.T) {
+		list, err := client.ListModels()
+		if err != nil {
+			t.Fatalf("ListModels() unexpected error: %v", err)
+		}
+		if len(list) != 1 || list[0].Name != "llama3.2:latest" || list[0].Details.ParameterSize != "3.2B" {
+			t.Errorf("ListModels() = %+v", list)
+		}
+	})
+
+	t.Run("ListRunningModels", func(t *testing.T) {
+		running, err := client.ListRunningModels()
+		if err != nil {
+			t.Fatalf("ListRunningModels() unexpected error: %v", err)
+		}
+		if len(running) != 1 || running[0].SizeVRAM != 50 {
+			t.Errorf("ListRunningModels() = %+v", running)
+		}
+	})
+
+	t.Run("ShowModel", func(t *testing.T) {
+		info, err := client.ShowModel("llama3.2")
+		if err != nil {
+			t.Fatalf("ShowModel() unexpected error: %v", err)
+		}
+		if info.Details.Family != "llama" || info.ModelInfo["llama.context_length"] != float64(131072) {
+			t.Errorf("ShowModel() = %+v", info)
+		}
+	})
+
+	t.Run("ShowModel not found", func(t *testing.T) {
+		_, err := client.ShowModel("missing")
+		if err == nil || !strings.Contains(err.Error(), "model 'missing' not found") {
+			t.Errorf("ShowModel() error = %v, want the server error message", err)
+		}
+	})
+
+	t.Run("PullModel", func(t *testing.T) {
+		var statuses []string
+		err := client.PullModel("llama3.2", func(p models.PullProgress) {
+			statuses = append(statuses, p.Status)
+		})
+		if err != nil {
+			t.Fatalf("PullModel() unexpected error: %v", err)
+		}
+		if want := []string{"pulling manifest", "downloading", "success"}; !reflect.DeepEqual(statuses, want) {
+			t.Errorf("PullModel() progress = %v, want %v", statuses, want)
+		}
+	})
+
+	t.Run("PullModel stream error", func(t *testing.T) {
+		err := client.PullModel("broken", nil)
+		if err == nil || !strings.Contains(err.Error(), "file does not exist") {
+			t.Errorf("PullModel() error = %v, want the streamed error", err)
+		}
+	})
+
+	t.Run("DeleteModel", func(t *testing.T) {
+		if err := client.DeleteModel("llama3.2"); err != nil {
+			t.Errorf("DeleteModel() unexpected error: %v", err)
+		}
+		if err := client.DeleteModel("missing"); err == nil || !strings.Contains(err.Error(), "404") {
+			t.Errorf("DeleteModel() error = %v, want a 404 error", err)
+		}
+	})
+}
+
+// TestHTTPClient_Version_NotOllama tests that servers other than Ollama fail the version check.
+func TestHTTPClient_Version_NotOllama(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{
+			name: "Proxy error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "Bad Gateway", http.StatusBadGateway)
+			},
+		},
+		{
+			name: "HTML page",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "<html><body>It works!</body></html>")
+			},
+		},
+		{
+			name: "JSON without a version",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"status": "ok"}`)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			if version, err := NewHTTPClient(server.URL).Version(); err == nil {
+				t.Errorf("Version() = %q, want an error", version)
+			}
+		})
+	}
+}
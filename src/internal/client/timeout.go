@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptrace"
+	"time"
+)
+
+// Timeouts limits the duration of the requests. A zero duration is no limit.
+type Timeouts struct {
+	Total      time.Duration // The whole request, until the response is read
+	Connect    time.Duration // Connecting to the server
+	FirstToken time.Duration // Waiting for the first bytes of the response, which includes loading the model
+	Idle       time.Duration // The longest gap between two chunks of the response
+}
+
+// The limits a TimeoutError reports, named after their flags
+const (
+	LimitTotal      = "timeout"
+	LimitConnect    = "connect-timeout"
+	LimitFirstToken = "first-token-timeout"
+	LimitIdle       = "idle-timeout"
+)
+
+// TimeoutError is returned when a request exceeds one of its timeouts.
+type TimeoutError struct {
+	Limit    string // The limit that was exceeded, e.g. LimitIdle
+	Duration time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	switch e.Limit {
+	case LimitConnect:
+		return fmt.Sprintf("could not connect to the server within %s (-%s)", e.Duration, e.Limit)
+	case LimitFirstToken:
+		return fmt.Sprintf("the server sent no response within %s (-%s)", e.Duration, e.Limit)
+	case LimitIdle:
+		return fmt.Sprintf("the response stalled for more than %s (-%s)", e.Duration, e.Limit)
+	default:
+		return fmt.Sprintf("the response was not complete within %s (-%s)", e.Duration, e.Limit)
+	}
+}
+
+// Timeout reports that the error is a timeout, like the net package errors.
+func (e *TimeoutError) Timeout() bool { return true }
+
+// watchdog cancels a request, with a TimeoutError as the cause, when it exceeds one
+// of its timeouts. Its methods are called by the goroutine reading the response.
+type watchdog struct {
+	timeouts Timeouts
+	cancel   context.CancelCauseFunc
+	timers   []*time.Timer
+	connect  *time.Timer
+	waiting  *time.Timer // The first-token timer, then the idle one
+	read     bool        // The first bytes of the response were read
+}
+
+// startWatchdog starts the timers of a request made with the returned context.
+func startWatchdog(ctx context.Context, timeouts Timeouts) (context.Context, *watchdog) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	w := &watchdog{timeouts: timeouts, cancel: cancel}
+	w.after(timeouts.Total, LimitTotal)
+	w.connect = w.after(timeouts.Connect, LimitConnect)
+	w.waiting = w.after(timeouts.FirstToken, LimitFirstToken)
+
+	if w.connect != nil {
+		ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			GotConn: func(httptrace.GotConnInfo) { w.connect.Stop() },
+		})
+	}
+	return ctx, w
+}
+
+// after cancels the request when the timeout elapses, unless it is zero.
+func (w *watchdog) after(timeout time.Duration, limit string) *time.Timer {
+	if timeout <= 0 {
+		return nil
+	}
+	timer := time.AfterFunc(timeout, func() {
+		w.cancel(&TimeoutError{Limit: limit, Duration: timeout})
+	})
+	w.timers = append(w.timers, timer)
+	return timer
+}
+
+// received restarts the idle timer when bytes of the response are read, replacing
+// the first-token timer with it for the first ones.
+func (w *watchdog) received() {
+	if !w.read {
+		w.read = true
+		if w.waiting != nil {
+			w.waiting.Stop()
+		}
+		w.waiting = w.after(w.timeouts.Idle, LimitIdle)
+		return
+	}
+	if w.waiting != nil {
+		w.waiting.Reset(w.timeouts.Idle)
+	}
+}
+
+// stop stops the timers and releases the context of the request.
+func (w *watchdog) stop() {
+	for _, timer := range w.timers {
+		timer.Stop()
+	}
+	w.cancel(nil)
+}
+
+// timeoutCause returns the TimeoutError that canceled the context, if any, or err.
+func timeoutCause(ctx context.Context, err error) error {
+	var timeoutErr *TimeoutError
+	if errors.As(context.Cause(ctx), &timeoutErr) {
+		return timeoutErr
+	}
+	return err
+}
+
+// watchedBody is a response body whose reads feed the watchdog of the request.
+type watchedBody struct {
+	io.ReadCloser
+	ctx context.Context
+	w   *watchdog
+}
+
+func (b *watchedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.w.received()
+	}
+	if err != nil && err != io.EOF {
+		err = timeoutCause(b.ctx, err)
+	}
+	return n, err
+}
+
+func (b *watchedBody) Close() error {
+	b.w.stop()
+	return b.ReadCloser.Close()
+}
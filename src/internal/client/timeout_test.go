@@ -0,0 +1,111 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lucianoayres/nino-cli/internal/logger"
+	"github.com/lucianoayres/nino-cli/internal/models"
+)
+
+// TestHTTPClient_Timeouts tests that each timeout aborts the request with an error naming it.
+func TestHTTPClient_Timeouts(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	chunk := []byte(`{"response": "Hello", "done": false}` + "\n")
+	tests := []struct {
+		name      string
+		timeouts  Timeouts
+		chunks    int           // The chunks sent before the server stalls
+		interval  time.Duration // The delay before each chunk
+		wantLimit string        // The limit that fires, "" for none
+	}{
+		{
+			name:      "No response before the first-token timeout",
+			timeouts:  Timeouts{FirstToken: 50 * time.Millisecond},
+			wantLimit: LimitFirstToken,
+		},
+		{
+			name:      "Stream stalls after the first chunk",
+			timeouts:  Timeouts{FirstToken: time.Second, Idle: 50 * time.Millisecond},
+			chunks:    1,
+			wantLimit: LimitIdle,
+		},
+		{
+			name:      "Steady stream exceeds the total timeout",
+			timeouts:  Timeouts{Total: 150 * time.Millisecond, Idle: 100 * time.Millisecond},
+			chunks:    20,
+			interval:  20 * time.Millisecond,
+			wantLimit: LimitTotal,
+		},
+		{
+			name:     "Stream within the timeouts",
+			timeouts: Timeouts{Total: time.Second, FirstToken: 500 * time.Millisecond, Idle: 100 * time.Millisecond},
+			chunks:   3,
+			interval: 20 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stalled := tt.wantLimit != ""
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for i := 0; i < tt.chunks; i++ {
+					time.Sleep(tt.interval)
+					w.Write(chunk)
+					w.(http.Flusher).Flush()
+				}
+				if stalled {
+					<-r.Context().Done()
+				}
+			}))
+			defer server.Close()
+
+			client := NewHTTPClient(server.URL + "/api/generate")
+			client.Timeouts = tt.timeouts
+			response, err := client.SendRequest(models.RequestPayload{Model: "llama3.2", Prompt: "Hi"})
+			if err == nil {
+				_, err = io.ReadAll(response.Body)
+				response.Body.Close()
+			}
+
+			if tt.wantLimit == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			var timeoutErr *TimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.Fatalf("Expected a TimeoutError, got %v", err)
+			}
+			if timeoutErr.Limit != tt.wantLimit {
+				t.Errorf("Expected the %s limit to fire, got %s (%v)", tt.wantLimit, timeoutErr.Limit, timeoutErr)
+			}
+		})
+	}
+}
+
+// TestTimeoutError tests the messages of the timeout errors.
+func TestTimeoutError(t *testing.T) {
+	tests := []struct {
+		err  *TimeoutError
+		want string
+	}{
+		{&TimeoutError{Limit: LimitTotal, Duration: 5 * time.Minute}, "the response was not complete within 5m0s (-timeout)"},
+		{&TimeoutError{Limit: LimitConnect, Duration: 10 * time.Second}, "could not connect to the server within 10s (-connect-timeout)"},
+		{&TimeoutError{Limit: LimitFirstToken, Duration: 2 * time.Minute}, "the server sent no response within 2m0s (-first-token-timeout)"},
+		{&TimeoutError{Limit: LimitIdle, Duration: 30 * time.Second}, "the response stalled for more than 30s (-idle-timeout)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -4,8 +4,14 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+
+	"github.com/lucianoayres/nino-cli/internal/files"
+	"github.com/lucianoayres/nino-cli/internal/promptfile"
+	"github.com/lucianoayres/nino-cli/internal/schema"
 )
 
 // Config holds the configuration for the request
@@ -15,6 +21,7 @@ type Config struct {
 	PromptFile     string
 	URL            string
 	Output         string
+	MarkIncomplete bool // Note in the output file when the output was cut short
 	DisableLoading bool
 	Stream         bool
 	Keep_Alive     string
@@ -22,7 +29,23 @@ type Config struct {
 	Silent         bool
 	ImagePaths     []string // New field for image paths
 	Format         string
-	Verbose        bool     // New field for verbose logging
+	Verbose        bool                   // New field for verbose logging
+	Chat           bool                   // Use the chat endpoint with a messages array
+	Session        string                 // Name of the persistent chat session
+	Interactive    bool                   // Chat interactively, reading the messages from stdin
+	Options        map[string]interface{} // Ollama generation options (temperature, seed, ...)
+	System         string                 // System prompt sent in Ollama's system field
+	FormatSchema   string                 // Path of the JSON schema file for structured outputs
+	Schema         *schema.Schema         // JSON schema used as the format and to validate the output
+	MaxAttempts    int                    // Attempts to get a valid structured output
+	FilePaths      []string               // Files, directories and globs attached to the prompt
+	FileBudget     int64                  // Maximum total size of the attached files, 0 for no limit
+	References     []Reference            // The @path references found in the prompt
+	Template       bool                   // The prompt was rendered as a text/template
+	Vars           map[string]string      // Variables of the prompt template
+	DataScope      string                 // ID of the project the context and sessions are scoped to, "" when global
+	Profile        string                 // Name of the profile of the configuration files in use
+	Headers        map[string]string      // HTTP headers sent to the Ollama server
 }
 
 // arrayFlags is a custom type for parsing multiple -image flags
@@ -37,120 +60,447 @@ func (i *arrayFlags) Set(value string) error {
 	return nil
 }
 
-// ParseArgs parses command-line arguments and returns a Config struct
-func ParseArgs() (*Config, error) {
-	// Check for environment variables
-	defaultModel := os.Getenv("NINO_MODEL")
-	if defaultModel == "" {
-		defaultModel = "llama3.2" // Fallback default
+// parseVars parses the -var key=value pairs. It returns nil when there are none.
+func parseVars(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("the -var flag must be in the form key=value, got '%s'", pair)
+		}
+		vars[key] = value
 	}
+	return vars, nil
+}
 
-	defaultURL := os.Getenv("NINO_URL")
-	if defaultURL == "" {
-		defaultURL = "http://localhost:11434/api/generate" // Fallback default
+// parseHeaders parses the -header 'Name: Value' flags. It returns nil when there are none.
+func parseHeaders(headers []string) (map[string]string, error) {
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	parsed := make(map[string]string, len(headers))
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("the -header flag must be in the form 'Name: Value', got '%s'", header)
+		}
+		parsed[http.CanonicalHeaderKey(name)] = strings.TrimSpace(value)
 	}
+	return parsed, nil
+}
+
+// Built-in defaults of the settings
+const (
+	defaultModel     = "llama3.2"
+	defaultURL       = "http://localhost:11434/api/generate"
+	defaultKeepAlive = "60m" // Keep Model Alive time in minutes
+)
 
-	defaultKeepAlive := os.Getenv("NINO_KEEP_ALIVE")
-	if defaultKeepAlive == "" {
-		defaultKeepAlive = "60m" // Keep Model Alive time in minutes
+// DefaultModel returns the model used when none is given, from the environment or
+// the configuration files. Configuration files that can't be read are ignored.
+func DefaultModel() string {
+	return layerDefault("model", defaultModel)
+}
+
+// DefaultURL returns the URL used when none is given, from the environment or the
+// configuration files. Configuration files that can't be read are ignored.
+func DefaultURL() string {
+	return layerDefault("url", defaultURL)
+}
+
+// layerDefault returns the value of a setting from the layers, or the default.
+func layerDefault(name, value string) string {
+	layers, err := LoadLayers("")
+	if err != nil {
+		return value
+	}
+	if setting, ok := resolveLayers(layers)[name]; ok && len(setting.values) > 0 {
+		return setting.values[len(setting.values)-1]
 	}
+	return value
+}
 
-	systemPrompt := os.Getenv("NINO_SYSTEM_PROMPT")
+// DefaultHeaders returns the HTTP headers sent to the Ollama server as set by the
+// environment or the configuration files. Configuration files that can't be read are ignored.
+func DefaultHeaders() map[string]string {
+	layers, err := LoadLayers("")
+	if err != nil {
+		return nil
+	}
+	var headers []string
+	for key, setting := range resolveLayers(layers) {
+		if name, ok := strings.CutPrefix(key, "header."); ok && len(setting.values) > 0 {
+			headers = append(headers, name+": "+setting.values[len(setting.values)-1])
+		}
+	}
+	parsed, err := parseHeaders(headers)
+	if err != nil {
+		return nil
+	}
+	return parsed
+}
+
+// DefaultDataScope returns the ID of the project the context and sessions are scoped
+// to by the configuration files, or "" when they are global. Configuration files that
+// can't be read leave them global.
+func DefaultDataScope() string {
+	scope, err := resolveDataScope(layerDefault("scope", "global"))
+	if err != nil {
+		return ""
+	}
+	return scope
+}
+
+// resolveDataScope returns the ID of the project the context and sessions are scoped
+// to with the "project" scope, or "" with the "global" scope.
+func resolveDataScope(scope string) (string, error) {
+	switch scope {
+	case "global":
+		return "", nil
+	case "project":
+		p := currentProject()
+		if p == nil {
+			return "", errors.New("the project scope requires a project: create a .nino.toml file or a .nino directory at its root")
+		}
+		return p.ID(), nil
+	}
+	return "", fmt.Errorf("the -scope flag must be 'global' or 'project', got '%s'", scope)
+}
+
+// flagValues holds the values of the command-line flags.
+type flagValues struct {
+	model          *string
+	prompt         *string
+	promptFile     *string
+	noStdin        *bool
+	template       *bool
+	vars           arrayFlags
+	url            *string
+	output         *string
+	markIncomplete *bool
+	disableLoading *bool
+	disableStream  *bool
+	disableContext *bool
+	silent         *bool
+	format         *string
+	formatSchema   *string
+	maxAttempts    *int
+	chat           *bool
+	session        *string
+	interactive    *bool
+	system         *string
+	systemFile     *string
+	noSystem       *bool
+	keepAlive      *string
+	scope          *string
+	profile        *string
+	headers        arrayFlags
+	generation     generationFlags
+	imagePaths     arrayFlags
+	filePaths      arrayFlags
+	fileBudget     byteSize
+	verbose        *bool
+}
+
+// defineFlags defines the command-line flags in the flag set.
+func defineFlags(fs *flag.FlagSet) *flagValues {
+	v := &flagValues{
+		vars:       arrayFlags{},
+		imagePaths: arrayFlags{},
+		filePaths:  arrayFlags{},
+		fileBudget: byteSize(files.DefaultBudget),
+	}
 
 	// Define the flags with their long forms
-	modelPtr := flag.String("model", defaultModel, "The model to use (default is llama3.2)")
-	promptPtr := flag.String("prompt", "", "The prompt to send (required)")
-	promptFilePtr := flag.String("prompt-file", "", "The path to a file containing the prompt (optional)")
-	urlPtr := flag.String("url", defaultURL, "The URL to send the request to (default is http://localhost:11434/api/generate)")
-	outputPtr := flag.String("output", "", "The file to save the output to (optional)")
-	disableLoadingPtr := flag.Bool("no-loading", false, "Disable the loading animation (optional)")
-	disableStreamPtr := flag.Bool("no-stream", false, "Disable streaming the output (optional)")
-	disableContextPtr := flag.Bool("no-context", false, "Disable the context from the previous request (optional)")
-	silentPtr := flag.Bool("silent", false, "Run in silent mode (no console output, requires -output)")
-	formatPtr := flag.String("format", "", "The format of the output (must be 'json')")
+	v.model = fs.String("model", defaultModel, "The model to use (default is llama3.2)")
+	v.prompt = fs.String("prompt", "", "The prompt to send (required)")
+	v.promptFile = fs.String("prompt-file", "", "The path to a file containing the prompt, or - to read it from stdin (optional)")
+	v.noStdin = fs.Bool("no-stdin", false, "Do not read the input piped to stdin (optional)")
+	v.template = fs.Bool("template", false, "Render the prompt as a Go text/template, the default for .tmpl prompt files (optional)")
+	fs.Var(&v.vars, "var", "A prompt template variable as key=value (optional, can be specified multiple times, implies -template)")
+	v.url = fs.String("url", defaultURL, "The URL to send the request to (default is http://localhost:11434/api/generate)")
+	v.output = fs.String("output", "", "The file to save the output to (optional)")
+	v.markIncomplete = fs.Bool("mark-incomplete", false, "Note in the output file when the output is cut short by an interruption (optional, requires -output)")
+	v.disableLoading = fs.Bool("no-loading", false, "Disable the loading animation (optional)")
+	v.disableStream = fs.Bool("no-stream", false, "Disable streaming the output (optional)")
+	v.disableContext = fs.Bool("no-context", false, "Disable the context from the previous request (optional)")
+	v.silent = fs.Bool("silent", false, "Run in silent mode (no console output, requires -output)")
+	v.format = fs.String("format", "", "The format of the output (must be 'json')")
+	v.formatSchema = fs.String("format-schema", "", "The path to a JSON schema file the output must conform to (optional)")
+	v.maxAttempts = fs.Int("max-attempts", 1, "Re-prompt the model until its JSON output is valid, up to this many attempts (optional)")
+	v.chat = fs.Bool("chat", false, "Send the prompt as a chat message to the chat endpoint (optional)")
+	v.session = fs.String("session", "", "The name of the chat session to continue (optional, implies -chat)")
+	v.interactive = fs.Bool("interactive", false, "Chat interactively, the default of -chat and of the chat command without a prompt (optional)")
+
+	v.system = fs.String("system", "", "The system prompt (optional, overrides NINO_SYSTEM_PROMPT)")
+	v.systemFile = fs.String("system-file", "", "The path to a file containing the system prompt (optional)")
+	v.noSystem = fs.Bool("no-system", false, "Do not send any system prompt (optional)")
+	v.keepAlive = fs.String("keep-alive", defaultKeepAlive, "How long the model stays loaded after the request (default is 60m)")
+	v.profile = fs.String("profile", "", "The profile of the configuration files to use (optional, overrides NINO_PROFILE)")
+	fs.Var(&v.headers, "header", "An HTTP header sent to the Ollama server as 'Name: Value' (optional, can be specified multiple times)")
+	v.scope = fs.String("scope", "global", "Where the context and sessions are stored: global, or project to keep them per project")
+
+	// Define the generation option flags
+	fs.Var(&v.generation.temperature, "temperature", "The sampling temperature (optional)")
+	fs.Var(&v.generation.seed, "seed", "The random seed, for reproducible outputs (optional)")
+	fs.Var(&v.generation.maxTokens, "max-tokens", "The maximum number of tokens to generate (optional)")
+	fs.Var(&v.generation.ctxSize, "ctx-size", "The size of the context window in tokens (optional)")
+	fs.Var(&v.generation.stop, "stop", "A stop sequence (optional, can be specified multiple times)")
+	fs.Var(&v.generation.options, "option", "Any Ollama option as key=value (optional, can be specified multiple times)")
 
 	// Define short forms for the existing flags
-	flag.StringVar(modelPtr, "m", defaultModel, "The model to use (short form)")
-	flag.StringVar(promptPtr, "p", "", "The prompt to send (short form, required)")
-	flag.StringVar(promptFilePtr, "pf", "", "The file containing the prompt (short form, optional)")
-	flag.StringVar(urlPtr, "u", defaultURL, "The URL to send the request to (short form)")
-	flag.StringVar(outputPtr, "o", "", "The file to save the output to (short form, optional)")
-	flag.BoolVar(disableLoadingPtr, "nl", false, "Disable the loading animation (short form)")
-	flag.BoolVar(disableStreamPtr, "ns", false, "Disable streaming the output (short form)")
-	flag.BoolVar(disableContextPtr, "nc", false, "Disable the context from the previous request (short form)")
-	flag.BoolVar(silentPtr, "s", false, "Run in silent mode (short form, requires -output)")
-	flag.StringVar(formatPtr, "f", "", "The format of the output (short form, must be 'json')")
+	fs.StringVar(v.model, "m", defaultModel, "The model to use (short form)")
+	fs.StringVar(v.prompt, "p", "", "The prompt to send (short form, required)")
+	fs.StringVar(v.promptFile, "pf", "", "The file containing the prompt (short form, optional)")
+	fs.StringVar(v.url, "u", defaultURL, "The URL to send the request to (short form)")
+	fs.StringVar(v.output, "o", "", "The file to save the output to (short form, optional)")
+	fs.BoolVar(v.disableLoading, "nl", false, "Disable the loading animation (short form)")
+	fs.BoolVar(v.disableStream, "ns", false, "Disable streaming the output (short form)")
+	fs.BoolVar(v.disableContext, "nc", false, "Disable the context from the previous request (short form)")
+	fs.BoolVar(v.silent, "s", false, "Run in silent mode (short form, requires -output)")
+	fs.StringVar(v.format, "f", "", "The format of the output (short form, must be 'json')")
+	fs.StringVar(v.formatSchema, "fs", "", "The path to a JSON schema file (short form, optional)")
+	fs.BoolVar(v.chat, "c", false, "Send the prompt as a chat message (short form)")
+	fs.StringVar(v.session, "S", "", "The name of the chat session to continue (short form)")
 
 	// Define the new -image flag which can be specified multiple times
-	imagePaths := arrayFlags{}
+	fs.Var(&v.imagePaths, "image", "Paths to local image files (can be specified multiple times)")
+	fs.Var(&v.imagePaths, "i", "Paths to local image files (short form)")
 
-	flag.Var(&imagePaths, "image", "Paths to local image files (can be specified multiple times)")
-	flag.Var(&imagePaths, "i", "Paths to local image files (short form)")
+	// Define the -file flag, which can also be specified multiple times
+	fs.Var(&v.filePaths, "file", "Files, directories or glob patterns to attach to the prompt (can be specified multiple times)")
+	fs.Var(&v.filePaths, "F", "Files, directories or glob patterns to attach (short form)")
+	fs.Var(&v.fileBudget, "file-budget", "The maximum total size of the attached files, e.g. 512K (0 for no limit)")
 
 	// Define the new -verbose and -v flags
-	verbosePtr := flag.Bool("verbose", false, "Enable verbose logging for debugging and performance validation")
-	flag.BoolVar(verbosePtr, "v", false, "Enable verbose logging (shorthand)")
+	v.verbose = fs.Bool("verbose", false, "Enable verbose logging for debugging and performance validation")
+	fs.BoolVar(v.verbose, "v", false, "Enable verbose logging (shorthand)")
 
-	// Customize the usage message (optional)
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
-		flag.PrintDefaults()
-	}
+	return v
+}
+
+// FlagError is an error parsing the command-line flags, which the flag set has
+// already reported along with its usage.
+type FlagError struct {
+	Err error
+}
+
+func (e *FlagError) Error() string { return e.Err.Error() }
+
+func (e *FlagError) Unwrap() error { return e.Err }
+
+// ParseArgs defines the flags of a prompt on the flag set, parses the arguments
+// (without the program name) and returns a Config struct
+func ParseArgs(fs *flag.FlagSet, args []string) (*Config, error) {
+	v := defineFlags(fs)
 
 	// Parse the flags
-	flag.Parse()
+	if err := fs.Parse(args); err != nil {
+		return nil, &FlagError{Err: err}
+	}
+
+	// The flags that weren't given are set from the configuration files and the environment
+	layers, err := LoadLayers(*v.profile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the prompt file, whose front matter takes precedence over the other layers
+	var promptFileBody string
+	if *v.prompt == "" && *v.promptFile != "" && *v.promptFile != "-" {
+		content, err := os.ReadFile(*v.promptFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading prompt file '%s': %v", *v.promptFile, err)
+		}
+		frontMatter, body, err := promptfile.ParseFrontMatter(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("error in prompt file '%s': %v", *v.promptFile, err)
+		}
+		if frontMatter != nil {
+			layer, err := frontMatterLayer(frontMatter, filepath.Dir(*v.promptFile), "prompt file "+*v.promptFile)
+			if err != nil {
+				return nil, fmt.Errorf("error in prompt file '%s': %v", *v.promptFile, err)
+			}
+			layers = append(layers, layer)
+		}
+		promptFileBody = body
+	}
+
+	if err := applyLayers(fs, layers); err != nil {
+		return nil, err
+	}
 
 	// Validate flags
-	if *silentPtr && *outputPtr == "" {
+	if *v.silent && *v.output == "" {
 		return nil, errors.New("the -silent flag requires the -output flag to be specified")
 	}
 
-	if *formatPtr != "" && *formatPtr != "json" {
+	if *v.markIncomplete && *v.output == "" {
+		return nil, errors.New("the -mark-incomplete flag requires the -output flag to be specified")
+	}
+
+	if *v.format != "" && *v.format != "json" {
 		return nil, errors.New("the -format flag must be set to 'json' if specified")
 	}
 
+	// Load the JSON schema used for structured outputs
+	var outputSchema *schema.Schema
+	if *v.formatSchema != "" {
+		content, err := os.ReadFile(*v.formatSchema)
+		if err != nil {
+			return nil, fmt.Errorf("error reading schema file '%s': %v", *v.formatSchema, err)
+		}
+		outputSchema, err = schema.Compile(content)
+		if err != nil {
+			return nil, fmt.Errorf("error in schema file '%s': %v", *v.formatSchema, err)
+		}
+	}
+
+	if *v.maxAttempts < 1 {
+		return nil, errors.New("the -max-attempts flag must be at least 1")
+	}
+	if *v.maxAttempts > 1 && *v.format == "" && outputSchema == nil {
+		return nil, errors.New("the -max-attempts flag requires -format json or -format-schema")
+	}
+
+	// Without a prompt, chat mode is interactive unless the input is piped
+	chat := *v.chat || *v.session != "" || *v.interactive
+	interactive := *v.interactive || (chat && *v.prompt == "" && *v.promptFile == "" && fs.NArg() == 0 && len(v.filePaths) == 0 && !stdinIsPiped())
+	if interactive && (*v.silent || *v.output != "" || *v.maxAttempts > 1 || *v.promptFile == "-") {
+		return nil, errors.New("an interactive chat can't be used with -silent, -output, -max-attempts or -prompt-file -")
+	}
+
+	dataScope, err := resolveDataScope(*v.scope)
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := parseHeaders(v.headers)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := v.generation.buildOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the input piped to stdin, unless the whole prompt is read from it with -prompt-file -
+	var input string
+	if !interactive && *v.promptFile != "-" && !*v.noStdin && stdinIsPiped() {
+		input, err = readStdin()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// If the prompt is not provided via flags, check positional arguments
-	if *promptPtr == "" && *promptFilePtr == "" {
-		args := flag.Args()
-		if len(args) == 0 {
+	if *v.prompt == "" && *v.promptFile == "" {
+		args := fs.Args()
+		if len(args) == 0 && strings.TrimSpace(input) == "" && len(v.filePaths) == 0 && !interactive {
 			return nil, errors.New("either the prompt or prompt file is required")
 		}
-		*promptPtr = strings.Join(args, " ")
+		*v.prompt = strings.Join(args, " ")
 	}
 
 	// If the prompt-file is provided, read the file content
-	if *promptPtr == "" && *promptFilePtr != "" {
-		content, err := os.ReadFile(*promptFilePtr)
+	fromFile := false
+	if *v.prompt == "" && *v.promptFile == "-" {
+		*v.prompt, err = readStdin()
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(*v.prompt) == "" {
+			return nil, errors.New("the prompt read from stdin is empty")
+		}
+	} else if *v.prompt == "" && *v.promptFile != "" {
+		*v.prompt = promptFileBody
+		fromFile = true
+	}
+
+	// Render the prompt template
+	vars, err := parseVars(v.vars)
+	if err != nil {
+		return nil, err
+	}
+	renderTemplate := *v.template || len(vars) > 0 || (fromFile && strings.HasSuffix(*v.promptFile, ".tmpl"))
+	if renderTemplate {
+		name, dir := "prompt", "."
+		if fromFile {
+			name, dir = filepath.Base(*v.promptFile), filepath.Dir(*v.promptFile)
+		}
+		*v.prompt, err = promptfile.Render(name, *v.prompt, vars, dir)
 		if err != nil {
-			return nil, fmt.Errorf("error reading prompt file '%s': %v", *promptFilePtr, err)
+			return nil, err
 		}
-		*promptPtr = string(content)
 	}
 
-	// Concatenate system prompt if it is set
-	if systemPrompt != "" {
-		*promptPtr = systemPrompt + " " + *promptPtr
+	// Expand the @path references of the prompt
+	prompt, references, err := expandReferences(*v.prompt)
+	if err != nil {
+		return nil, err
+	}
+	*v.prompt = prompt
+
+	// Combine the piped input with the instruction prompt
+	if strings.TrimSpace(input) != "" {
+		*v.prompt = combinePrompt(*v.prompt, input)
+	}
+
+	// Resolve the system prompt: -no-system wins over -system-file, which wins over -system
+	system := *v.system
+	if *v.systemFile != "" {
+		content, err := os.ReadFile(*v.systemFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading system prompt file '%s': %v", *v.systemFile, err)
+		}
+		system = strings.TrimRight(string(content), "\r\n")
+	}
+	if *v.noSystem {
+		system = ""
 	}
 
 	// Concatenate image paths to the prompt if any
-	if len(imagePaths) > 0 {
-		*promptPtr = strings.TrimSpace(*promptPtr + " " + strings.Join(imagePaths, " "))
+	if len(v.imagePaths) > 0 {
+		*v.prompt = strings.TrimSpace(*v.prompt + " " + strings.Join(v.imagePaths, " "))
 	}
 
 	// Return the Config struct with all fields populated, including Verbose
 	return &Config{
-		Model:          *modelPtr,
-		Prompt:         *promptPtr,
-		PromptFile:     *promptFilePtr,
-		URL:            *urlPtr,
-		Output:         *outputPtr,
-		DisableLoading: *disableLoadingPtr,
-		Stream:         !*disableStreamPtr,
-		Keep_Alive:     defaultKeepAlive,
-		DisableContext: *disableContextPtr,
-		Silent:         *silentPtr,
-		ImagePaths:     imagePaths, // Assign the collected image paths
-		Format:         *formatPtr,
-		Verbose:        *verbosePtr, // Assign the Verbose flag
+		Model:          *v.model,
+		Prompt:         *v.prompt,
+		PromptFile:     *v.promptFile,
+		URL:            *v.url,
+		Output:         *v.output,
+		MarkIncomplete: *v.markIncomplete,
+		DisableLoading: *v.disableLoading,
+		Stream:         !*v.disableStream,
+		Keep_Alive:     *v.keepAlive,
+		DisableContext: *v.disableContext,
+		Silent:         *v.silent,
+		ImagePaths:     v.imagePaths, // Assign the collected image paths
+		Format:         *v.format,
+		Verbose:        *v.verbose, // Assign the Verbose flag
+		Chat:           chat,
+		Session:        *v.session,
+		Interactive:    interactive,
+		Options:        options,
+		System:         system,
+		FormatSchema:   *v.formatSchema,
+		Schema:         outputSchema,
+		MaxAttempts:    *v.maxAttempts,
+		FilePaths:      v.filePaths,
+		FileBudget:     int64(v.fileBudget),
+		References:     references,
+		Template:       renderTemplate,
+		Vars:           vars,
+		DataScope:      dataScope,
+		Profile:        *v.profile,
+		Headers:        headers,
 	}, nil
 }
@@ -1,16 +1,23 @@
 package config
 
 import (
+	"errors"
 	"flag"
-	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
+
+	"github.com/lucianoayres/nino-cli/internal/files"
 )
 
 func TestParseArgs(t *testing.T) {
+	// Keep the user configuration file out of the tests
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
 	// Create a temporary prompt file for testing
 	tmpDir := t.TempDir()
 	promptFilePath := filepath.Join(tmpDir, "prompt.txt")
@@ -32,6 +39,38 @@ func TestParseArgs(t *testing.T) {
 		t.Fatalf("Failed to create temporary image file 2: %v", err)
 	}
 
+	systemFilePath := filepath.Join(tmpDir, "system.txt")
+	err = os.WriteFile(systemFilePath, []byte("You are a release manager.\n"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create temporary system prompt file: %v", err)
+	}
+
+	referencePromptPath := filepath.Join(tmpDir, "reference.txt")
+	err = os.WriteFile(referencePromptPath, []byte("Review @"+systemFilePath), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create temporary prompt file: %v", err)
+	}
+
+	templatePath := filepath.Join(tmpDir, "release.tmpl")
+	err = os.WriteFile(templatePath, []byte("Write release notes for {{.version}}."), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create temporary prompt template: %v", err)
+	}
+
+	recipePath := filepath.Join(tmpDir, "release-notes.prompt")
+	recipe := "---\nmodel: llama3.1\nsystem: |\n  You write release notes.\nformat: json\nkeep_alive: 5m\n" +
+		"images: [image1.jpg]\noptions:\n  temperature: 0.2\n  num_ctx: 8192\n  top_k: 20\n---\nSummarize the changes.\n"
+	err = os.WriteFile(recipePath, []byte(recipe), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create temporary recipe file: %v", err)
+	}
+
+	invalidSchemaPath := filepath.Join(tmpDir, "invalid-schema.json")
+	err = os.WriteFile(invalidSchemaPath, []byte(`{"type": "object", "properties": {"id": {"pattern": "("}}}`), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create temporary schema file: %v", err)
+	}
+
 	tests := []struct {
 		name            string
 		args            []string
@@ -39,13 +78,14 @@ func TestParseArgs(t *testing.T) {
 		envURL          string
 		envSystemPrompt string
 		envKeepAlive    string
+		stdin           string // Input piped to stdin, if any
 		wantConfig      *Config
 		wantErr         bool
 		wantErrMessage  string
 	}{
 		{
 			name: "Valid arguments with long flags",
-			args: []string{"cmd", "--model=llama3.1", "--prompt=Hello", "--url=http://localhost:11434/api/generate", "--output=result.txt", "--format=json", "--no-stream"},
+			args: []string{"--model=llama3.1", "--prompt=Hello", "--url=http://localhost:11434/api/generate", "--output=result.txt", "--format=json", "--no-stream"},
 			wantConfig: &Config{
 				Model:          "llama3.1",
 				Prompt:         "Hello",
@@ -59,19 +99,22 @@ func TestParseArgs(t *testing.T) {
 				Verbose:        false,
 				Stream:         false,
 				Keep_Alive:     "60m",
+				MaxAttempts:    1,
+				FilePaths:      []string{},
+				FileBudget:     files.DefaultBudget,
 			},
 			wantErr: false,
 		},
 		{
 			name:            "Environment variables including system prompt",
-			args:            []string{"cmd", "--prompt=Hello", "--format=json"},
+			args:            []string{"--prompt=Hello", "--format=json"},
 			envModel:        "env_model",
 			envURL:          "http://env-url/api",
 			envSystemPrompt: "System prompt:",
 			envKeepAlive:    "30m",
 			wantConfig: &Config{
 				Model:          "env_model",
-				Prompt:         strings.TrimSpace("System prompt: Hello"),
+				Prompt:         "Hello",
 				PromptFile:     "",
 				URL:            "http://env-url/api",
 				Output:         "",
@@ -82,12 +125,16 @@ func TestParseArgs(t *testing.T) {
 				Verbose:        false,
 				Stream:         true,
 				Keep_Alive:     "30m",
+				MaxAttempts:    1,
+				FilePaths:      []string{},
+				FileBudget:     files.DefaultBudget,
+				System:         "System prompt:",
 			},
 			wantErr: false,
 		},
 		{
 			name: "Valid arguments with image files",
-			args: []string{"cmd", "--prompt=Hello", "--image", imageFilePath1, "--image", imageFilePath2, "--format=json", "--no-stream"},
+			args: []string{"--prompt=Hello", "--image", imageFilePath1, "--image", imageFilePath2, "--format=json", "--no-stream"},
 			wantConfig: &Config{
 				Model:          "llama3.2",
 				Prompt:         strings.TrimSpace("Hello " + imageFilePath1 + " " + imageFilePath2),
@@ -101,23 +148,402 @@ func TestParseArgs(t *testing.T) {
 				Verbose:        false,
 				Stream:         false,
 				Keep_Alive:     "60m",
+				MaxAttempts:    1,
+				FilePaths:      []string{},
+				FileBudget:     files.DefaultBudget,
 			},
 			wantErr: false,
 		},
+		{
+			name:            "System flag overrides environment variable",
+			args:            []string{"--system=Answer in French.", "Hello"},
+			envSystemPrompt: "System prompt:",
+			wantConfig: &Config{
+				Model:       "llama3.2",
+				Prompt:      "Hello",
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{},
+				Stream:      true,
+				Keep_Alive:  "60m",
+				MaxAttempts: 1,
+				FilePaths:   []string{},
+				FileBudget:  files.DefaultBudget,
+				System:      "Answer in French.",
+			},
+			wantErr: false,
+		},
+		{
+			name:            "System file overrides system flag",
+			args:            []string{"--system=Answer in French.", "--system-file", systemFilePath, "Hello"},
+			envSystemPrompt: "System prompt:",
+			wantConfig: &Config{
+				Model:       "llama3.2",
+				Prompt:      "Hello",
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{},
+				Stream:      true,
+				Keep_Alive:  "60m",
+				MaxAttempts: 1,
+				FilePaths:   []string{},
+				FileBudget:  files.DefaultBudget,
+				System:      "You are a release manager.",
+			},
+			wantErr: false,
+		},
+		{
+			name:            "No system flag disables the system prompt",
+			args:            []string{"--no-system", "--system-file", systemFilePath, "Hello"},
+			envSystemPrompt: "System prompt:",
+			wantConfig: &Config{
+				Model:       "llama3.2",
+				Prompt:      "Hello",
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{},
+				Stream:      true,
+				Keep_Alive:  "60m",
+				MaxAttempts: 1,
+				FilePaths:   []string{},
+				FileBudget:  files.DefaultBudget,
+				System:      "",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Generation options and keep alive flags",
+			args: []string{"--prompt=Hello", "--temperature=0", "--seed=42", "--max-tokens=100", "--ctx-size=8192", "--stop=END", "--option", "top_k=40", "--keep-alive=5m"},
+			wantConfig: &Config{
+				Model:       "llama3.2",
+				Prompt:      "Hello",
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{},
+				Stream:      true,
+				Keep_Alive:  "5m",
+				MaxAttempts: 1,
+				FilePaths:   []string{},
+				FileBudget:  files.DefaultBudget,
+				Options: map[string]interface{}{
+					"temperature": 0.0,
+					"seed":        42,
+					"num_predict": 100,
+					"num_ctx":     8192,
+					"stop":        []string{"END"},
+					"top_k":       40,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Invalid schema file",
+			args:    []string{"--format-schema", invalidSchemaPath, "Hello"},
+			wantErr: true,
+		},
+		{
+			name:    "Missing schema file",
+			args:    []string{"--format-schema", filepath.Join(tmpDir, "missing.json"), "Hello"},
+			wantErr: true,
+		},
+		{
+			name:  "Piped input combined with the prompt",
+			args:  []string{"write a commit message"},
+			stdin: "diff --git a/main.go b/main.go\n",
+			wantConfig: &Config{
+				Model:       "llama3.2",
+				Prompt:      "write a commit message\n\n<input>\ndiff --git a/main.go b/main.go\n</input>",
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{},
+				Stream:      true,
+				Keep_Alive:  "60m",
+				MaxAttempts: 1,
+				FilePaths:   []string{},
+				FileBudget:  files.DefaultBudget,
+			},
+			wantErr: false,
+		},
+		{
+			name:  "Piped input without a prompt",
+			args:  nil,
+			stdin: "Hello from stdin\n",
+			wantConfig: &Config{
+				Model:       "llama3.2",
+				Prompt:      "Hello from stdin",
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{},
+				Stream:      true,
+				Keep_Alive:  "60m",
+				MaxAttempts: 1,
+				FilePaths:   []string{},
+				FileBudget:  files.DefaultBudget,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Chat without a prompt is interactive",
+			args: []string{"--chat"},
+			wantConfig: &Config{
+				Model:       "llama3.2",
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{},
+				Stream:      true,
+				Keep_Alive:  "60m",
+				Chat:        true,
+				Interactive: true,
+				MaxAttempts: 1,
+				FilePaths:   []string{},
+				FileBudget:  files.DefaultBudget,
+			},
+			wantErr: false,
+		},
+		{
+			name:  "Chat with piped input is not interactive",
+			args:  []string{"--chat"},
+			stdin: "Hello from stdin\n",
+			wantConfig: &Config{
+				Model:       "llama3.2",
+				Prompt:      "Hello from stdin",
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{},
+				Stream:      true,
+				Keep_Alive:  "60m",
+				Chat:        true,
+				MaxAttempts: 1,
+				FilePaths:   []string{},
+				FileBudget:  files.DefaultBudget,
+			},
+			wantErr: false,
+		},
+		{
+			name:           "Interactive chat with an output file",
+			args:           []string{"--interactive", "--output", "chat.txt"},
+			wantErr:        true,
+			wantErrMessage: "an interactive chat can't be used with -silent, -output, -max-attempts or -prompt-file -",
+		},
+		{
+			name:  "Prompt file read from stdin",
+			args:  []string{"--prompt-file", "-"},
+			stdin: "Hello from stdin\n",
+			wantConfig: &Config{
+				Model:       "llama3.2",
+				Prompt:      "Hello from stdin\n",
+				PromptFile:  "-",
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{},
+				Stream:      true,
+				Keep_Alive:  "60m",
+				MaxAttempts: 1,
+				FilePaths:   []string{},
+				FileBudget:  files.DefaultBudget,
+			},
+			wantErr: false,
+		},
+		{
+			name:  "No stdin flag ignores piped input",
+			args:  []string{"--no-stdin", "Hello"},
+			stdin: "ignored",
+			wantConfig: &Config{
+				Model:       "llama3.2",
+				Prompt:      "Hello",
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{},
+				Stream:      true,
+				Keep_Alive:  "60m",
+				MaxAttempts: 1,
+				FilePaths:   []string{},
+				FileBudget:  files.DefaultBudget,
+			},
+			wantErr: false,
+		},
+		{
+			name: "References in the prompt file",
+			args: []string{"--prompt-file", referencePromptPath},
+			wantConfig: &Config{
+				Model:       "llama3.2",
+				Prompt:      "Review \nFile: " + systemFilePath + "\n```txt\nYou are a release manager.\n```\n",
+				PromptFile:  referencePromptPath,
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{},
+				Stream:      true,
+				Keep_Alive:  "60m",
+				MaxAttempts: 1,
+				FilePaths:   []string{},
+				FileBudget:  files.DefaultBudget,
+				References:  []Reference{{Token: "@" + systemFilePath, Path: systemFilePath, Resolved: true}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Prompt template with variables",
+			args: []string{"--prompt-file", templatePath, "--var", "version=1.2.0"},
+			wantConfig: &Config{
+				Model:       "llama3.2",
+				Prompt:      "Write release notes for 1.2.0.",
+				PromptFile:  templatePath,
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{},
+				Stream:      true,
+				Keep_Alive:  "60m",
+				MaxAttempts: 1,
+				FilePaths:   []string{},
+				FileBudget:  files.DefaultBudget,
+				Template:    true,
+				Vars:        map[string]string{"version": "1.2.0"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Template flag renders the prompt",
+			args: []string{"--template", "Running on {{os}}"},
+			wantConfig: &Config{
+				Model:       "llama3.2",
+				Prompt:      "Running on " + runtime.GOOS,
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{},
+				Stream:      true,
+				Keep_Alive:  "60m",
+				MaxAttempts: 1,
+				FilePaths:   []string{},
+				FileBudget:  files.DefaultBudget,
+				Template:    true,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Undefined template variable",
+			args:    []string{"--prompt-file", templatePath},
+			wantErr: true,
+		},
+		{
+			name:           "Invalid var flag",
+			args:           []string{"--var", "version", "Hello"},
+			wantErr:        true,
+			wantErrMessage: "the -var flag must be in the form key=value, got 'version'",
+		},
+		{
+			name: "Mark incomplete output",
+			args: []string{"--mark-incomplete", "--output=result.txt", "Hello"},
+			wantConfig: &Config{
+				Model:          "llama3.2",
+				Prompt:         "Hello",
+				URL:            "http://localhost:11434/api/generate",
+				Output:         "result.txt",
+				MarkIncomplete: true,
+				ImagePaths:     []string{},
+				Stream:         true,
+				Keep_Alive:     "60m",
+				MaxAttempts:    1,
+				FilePaths:      []string{},
+				FileBudget:     files.DefaultBudget,
+			},
+			wantErr: false,
+		},
+		{
+			name:           "Mark incomplete without output",
+			args:           []string{"--mark-incomplete", "Hello"},
+			wantErr:        true,
+			wantErrMessage: "the -mark-incomplete flag requires the -output flag to be specified",
+		},
+		{
+			name:     "Prompt file with front matter",
+			args:     []string{"--prompt-file", recipePath},
+			envModel: "mistral",
+			wantConfig: &Config{
+				Model:       "llama3.1",
+				Prompt:      "Summarize the changes.\n " + imageFilePath1,
+				PromptFile:  recipePath,
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{imageFilePath1},
+				Format:      "json",
+				Stream:      true,
+				Keep_Alive:  "5m",
+				MaxAttempts: 1,
+				System:      "You write release notes.",
+				Options:     map[string]interface{}{"temperature": 0.2, "num_ctx": 8192, "top_k": 20},
+				FilePaths:   []string{},
+				FileBudget:  files.DefaultBudget,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Flags override the front matter",
+			args: []string{"--prompt-file", recipePath, "-m", "phi3", "--temperature=0.9", "--option=top_k=5", "--no-system", "--keep-alive=1m", "--image", imageFilePath2},
+			wantConfig: &Config{
+				Model:       "phi3",
+				Prompt:      "Summarize the changes.\n " + imageFilePath2,
+				PromptFile:  recipePath,
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{imageFilePath2},
+				Format:      "json",
+				Stream:      true,
+				Keep_Alive:  "1m",
+				MaxAttempts: 1,
+				Options:     map[string]interface{}{"temperature": 0.9, "num_ctx": 8192, "top_k": 5},
+				FilePaths:   []string{},
+				FileBudget:  files.DefaultBudget,
+			},
+			wantErr: false,
+		},
+		{
+			name: "File flags",
+			args: []string{"-F", "main.go", "--file=internal/**/*.go", "--file-budget=64K", "Review this code"},
+			wantConfig: &Config{
+				Model:       "llama3.2",
+				Prompt:      "Review this code",
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{},
+				Stream:      true,
+				Keep_Alive:  "60m",
+				MaxAttempts: 1,
+				FilePaths:   []string{"main.go", "internal/**/*.go"},
+				FileBudget:  64 << 10,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Files without a prompt",
+			args: []string{"-F", "main.go"},
+			wantConfig: &Config{
+				Model:       "llama3.2",
+				URL:         "http://localhost:11434/api/generate",
+				ImagePaths:  []string{},
+				Stream:      true,
+				Keep_Alive:  "60m",
+				MaxAttempts: 1,
+				FilePaths:   []string{"main.go"},
+				FileBudget:  files.DefaultBudget,
+			},
+			wantErr: false,
+		},
+		{
+			name:           "Empty prompt file read from stdin",
+			args:           []string{"--prompt-file", "-"},
+			stdin:          "\n",
+			wantErr:        true,
+			wantErrMessage: "the prompt read from stdin is empty",
+		},
+		{
+			name:           "Empty piped input without a prompt",
+			args:           nil,
+			stdin:          "  \n",
+			wantErr:        true,
+			wantErrMessage: "either the prompt or prompt file is required",
+		},
+		{
+			name:           "Invalid option flag",
+			args:           []string{"--prompt=Hello", "--option=top_k"},
+			wantErr:        true,
+			wantErrMessage: "the -option flag must be in the form key=value, got 'top_k'",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Save and restore os.Args, environment variables, and flag.CommandLine
-			origArgs := os.Args
+			// Save and restore the environment variables
 			origEnvModel := os.Getenv("NINO_MODEL")
 			origEnvURL := os.Getenv("NINO_URL")
 			origEnvSystemPrompt := os.Getenv("NINO_SYSTEM_PROMPT")
 			origEnvKeepAlive := os.Getenv("NINO_KEEP_ALIVE")
-			origFlagCommandLine := flag.CommandLine
+			origStdin, origStdinIsPiped := stdin, stdinIsPiped
 
 			defer func() {
-				os.Args = origArgs
 				if origEnvModel != "" {
 					os.Setenv("NINO_MODEL", origEnvModel)
 				} else {
@@ -138,18 +564,12 @@ func TestParseArgs(t *testing.T) {
 				} else {
 					os.Unsetenv("NINO_KEEP_ALIVE")
 				}
-				flag.CommandLine = origFlagCommandLine
+				stdin, stdinIsPiped = origStdin, origStdinIsPiped
 			}()
 
-			// Reset flags before each test
-			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-			flag.CommandLine.Usage = func() {
-				fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
-				flag.CommandLine.PrintDefaults()
-			}
-
-			// Set os.Args to the test case arguments
-			os.Args = tt.args
+			// Pipe the test case input to stdin, if any
+			stdin = strings.NewReader(tt.stdin)
+			stdinIsPiped = func() bool { return tt.stdin != "" }
 
 			// Set environment variables if specified
 			if tt.envModel != "" {
@@ -174,7 +594,9 @@ func TestParseArgs(t *testing.T) {
 			}
 
 			// Parse the arguments
-			gotConfig, err := ParseArgs()
+			fs := flag.NewFlagSet("run", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			gotConfig, err := ParseArgs(fs, tt.args)
 			if tt.wantErr {
 				if err == nil {
 					t.Errorf("ParseArgs() expected error but got none")
@@ -196,3 +618,90 @@ func TestParseArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveDataScope(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".nino"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, filepath.Join(root, "src"))
+
+	scope, err := resolveDataScope("project")
+	if err != nil {
+		t.Fatalf("resolveDataScope() unexpected error: %v", err)
+	}
+	if want := filepath.Base(root) + "-"; !strings.HasPrefix(scope, want) {
+		t.Errorf("resolveDataScope() = %s, want the ID of the project %s", scope, root)
+	}
+
+	if scope, err := resolveDataScope("global"); err != nil || scope != "" {
+		t.Errorf("resolveDataScope(global) = %q, %v, want an empty scope", scope, err)
+	}
+	if _, err := resolveDataScope("team"); err == nil {
+		t.Error("resolveDataScope(team) expected an error")
+	}
+
+	chdir(t, t.TempDir())
+	if _, err := resolveDataScope("project"); err == nil {
+		t.Error("resolveDataScope(project) expected an error outside of a project")
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "No headers", want: nil},
+		{
+			name:    "Names are canonicalized and values trimmed",
+			headers: []string{"authorization:  Bearer secret ", "X-Team:docs", "X-Empty:"},
+			want:    map[string]string{"Authorization": "Bearer secret", "X-Team": "docs", "X-Empty": ""},
+		},
+		{
+			name:    "Value containing a colon",
+			headers: []string{"X-Url: http://proxy:8080"},
+			want:    map[string]string{"X-Url": "http://proxy:8080"},
+		},
+		{name: "Missing colon", headers: []string{"Authorization Bearer"}, wantErr: true},
+		{name: "Missing name", headers: []string{": value"}, wantErr: true},
+		{name: "Space in the name", headers: []string{"X Team: docs"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHeaders(tt.headers)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseHeaders() expected error but got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseHeaders() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseHeaders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseArgs_FlagError(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	for _, args := range [][]string{{"-h"}, {"-modle", "llama3.2"}} {
+		fs := flag.NewFlagSet("run", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		_, err := ParseArgs(fs, args)
+		var flagErr *FlagError
+		if !errors.As(err, &flagErr) {
+			t.Errorf("ParseArgs(%q) error = %v, want a FlagError", args, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/lucianoayres/nino-cli/internal/promptfile"
+)
+
+// optionFlags maps Ollama options to the flags dedicated to them
+var optionFlags = map[string]string{
+	"temperature": "temperature",
+	"seed":        "seed",
+	"num_predict": "max-tokens",
+	"num_ctx":     "ctx-size",
+	"stop":        "stop",
+}
+
+// frontMatterLayer returns the settings of the front matter of a prompt file as a
+// layer. Relative paths are resolved from dir, the directory of the prompt file.
+func frontMatterLayer(fm *promptfile.FrontMatter, dir, source string) (Layer, error) {
+	values := map[string][]string{}
+	if fm.Model != "" {
+		values["model"] = []string{fm.Model}
+	}
+	if fm.System != "" {
+		values["system"] = []string{fm.System}
+	}
+	if fm.Format != "" {
+		values["format"] = []string{fm.Format}
+	}
+	if fm.Schema != "" {
+		values["format-schema"] = []string{resolvePath(dir, fm.Schema)}
+	}
+	if fm.KeepAlive != "" {
+		values["keep-alive"] = []string{fm.KeepAlive}
+	}
+	for _, image := range fm.Images {
+		values["image"] = append(values["image"], resolvePath(dir, image))
+	}
+
+	// Options with a dedicated flag are set through it, the others through -option
+	for _, name := range sortedKeys(fm.Options) {
+		if err := addOption(values, name, fm.Options[name]); err != nil {
+			return Layer{}, err
+		}
+	}
+	return Layer{Source: source, Values: values}, nil
+}
+
+// optionGiven reports whether the option was given with an -option key=value flag.
+func optionGiven(options []string, name string) bool {
+	for _, option := range options {
+		if key, _, _ := strings.Cut(option, "="); strings.TrimSpace(key) == name {
+			return true
+		}
+	}
+	return false
+}
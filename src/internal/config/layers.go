@@ -0,0 +1,482 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lucianoayres/nino-cli/internal/configfile"
+	"github.com/lucianoayres/nino-cli/internal/project"
+	"github.com/lucianoayres/nino-cli/internal/utils"
+)
+
+// Layer holds settings from a source below the command line, such as a
+// configuration file or an environment variable.
+type Layer struct {
+	Source   string                         // Where the settings come from, e.g. "env NINO_URL"
+	Values   map[string][]string            // Values by flag name; "option", "var" and "header" hold key/value pairs
+	Profiles map[string]map[string][]string // Values of the profiles defined by a configuration file, by profile name
+}
+
+// configFileNames are the names of the user configuration file, by preference
+var configFileNames = []string{"config.toml", "config.yaml", "config.yml"}
+
+// envSettings maps the environment variables to the settings they define
+var envSettings = []struct {
+	envVar string
+	name   string
+}{
+	{"NINO_MODEL", "model"},
+	{"NINO_URL", "url"},
+	{"NINO_KEEP_ALIVE", "keep-alive"},
+	{"NINO_SYSTEM_PROMPT", "system"},
+	{"NINO_TEMPERATURE", "temperature"},
+	{"NINO_SEED", "seed"},
+	{"NINO_MAX_TOKENS", "max-tokens"},
+	{"NINO_CTX_SIZE", "ctx-size"},
+	{"NINO_PROFILE", "profile"},
+}
+
+// tableSettings maps the tables of a configuration file to the key/value flags they set
+var tableSettings = map[string]string{"options": "option", "vars": "var", "headers": "header"}
+
+// pairSeparators are the separators of the key/value flags, whose values are merged
+// by key across the layers
+var pairSeparators = map[string]string{"option": "=", "var": "=", "header": ": "}
+
+// commandLineOnly are the flags that can't be set outside the command line
+var commandLineOnly = map[string]bool{"prompt": true, "prompt-file": true}
+
+// pathFlags are the flags taking paths, which are relative to the file setting them
+var pathFlags = map[string]bool{"system-file": true, "format-schema": true, "image": true, "file": true}
+
+// flagGroups are flags for the same setting: when one of them is given on the
+// command line, the others aren't set from the layers
+var flagGroups = [][]string{
+	{"system", "system-file", "no-system"},
+	{"format", "format-schema"},
+}
+
+// UserConfigFile returns the path of the user configuration file: the first of
+// config.toml, config.yaml and config.yml found in the nino configuration
+// directory, or config.toml when there is none.
+func UserConfigFile() (string, error) {
+	configDir, err := utils.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(configDir, "nino")
+	if path := findFile(dir, configFileNames); path != "" {
+		return path, nil
+	}
+	return filepath.Join(dir, configFileNames[0]), nil
+}
+
+// ProjectConfigFile returns the path of the configuration file of the project of
+// the working directory, or "" when there is none.
+func ProjectConfigFile() string {
+	if p := currentProject(); p != nil {
+		return p.ConfigFile
+	}
+	return ""
+}
+
+// currentProject returns the project of the working directory, or nil when there is none.
+func currentProject() *project.Project {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	return project.Find(wd)
+}
+
+// findFile returns the path of the first of the files found in dir, or "" when there is none.
+func findFile(dir string, names []string) string {
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// LoadLayers returns the layers of settings below the command line, from the lowest
+// precedence: the user configuration file, the project configuration file, the
+// environment variables and the selected profile. The profile is the named one, or
+// when name is empty the one selected by the environment or the configuration files.
+func LoadLayers(profile string) ([]Layer, error) {
+	layers, err := loadBaseLayers()
+	if err != nil {
+		return nil, err
+	}
+
+	if profile == "" {
+		if setting, ok := resolveLayers(layers)["profile"]; ok && len(setting.values) > 0 {
+			profile = setting.values[len(setting.values)-1]
+		}
+	}
+	if profile != "" {
+		layer, err := profileLayer(layers, profile)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+// loadBaseLayers returns the layers of the configuration files and of the
+// environment variables, without the profile.
+func loadBaseLayers() ([]Layer, error) {
+	var layers []Layer
+
+	// Without a home directory, there is no user configuration
+	userFile, _ := UserConfigFile()
+	files := []struct {
+		kind string
+		path string
+	}{
+		{"user config", userFile},
+		{"project config", ProjectConfigFile()},
+	}
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+		layer, err := readConfigFile(file.path, file.kind)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+
+	for _, env := range envSettings {
+		if value := os.Getenv(env.envVar); value != "" {
+			layers = append(layers, Layer{Source: "env " + env.envVar, Values: map[string][]string{env.name: {value}}})
+		}
+	}
+	return layers, nil
+}
+
+// readConfigFile reads a TOML or YAML configuration file, by its extension, as a layer.
+func readConfigFile(path, kind string) (Layer, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Layer{}, err
+	}
+	var document map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		document, err = configfile.ParseYAML(string(content), 1)
+	default:
+		document, err = configfile.ParseTOML(string(content))
+	}
+	if err != nil {
+		return Layer{}, fmt.Errorf("error in %s '%s': %v", kind, path, err)
+	}
+
+	values, profiles, err := documentValues(document, filepath.Dir(path))
+	if err != nil {
+		return Layer{}, fmt.Errorf("error in %s '%s': %v", kind, path, err)
+	}
+	return Layer{Source: kind + " " + path, Values: values, Profiles: profiles}, nil
+}
+
+// documentValues converts the settings of a configuration file to flag values,
+// along with the settings of its profiles. Keys are flag names, in which
+// underscores can replace the dashes, except for the [options], [vars], [headers]
+// and [profiles] tables. Relative paths are resolved from dir.
+func documentValues(document map[string]interface{}, dir string) (map[string][]string, map[string]map[string][]string, error) {
+	values := map[string][]string{}
+	var profiles map[string]map[string][]string
+	for _, key := range sortedKeys(document) {
+		if key == "profiles" {
+			var err error
+			if profiles, err = profileValues(document[key], dir); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if flagName, ok := tableSettings[key]; ok {
+			table, ok := document[key].(map[string]interface{})
+			if !ok {
+				return nil, nil, fmt.Errorf("'%s' must be a table of settings", key)
+			}
+			for _, name := range sortedKeys(table) {
+				var err error
+				if flagName == "option" {
+					err = addOption(values, name, table[name])
+				} else {
+					err = addPair(values, flagName, name, table[name])
+				}
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+			continue
+		}
+
+		name := strings.ReplaceAll(key, "_", "-")
+		list, err := settingValues(document[key])
+		if err != nil {
+			return nil, nil, fmt.Errorf("the setting '%s' %v", key, err)
+		}
+		if pathFlags[name] {
+			for i, path := range list {
+				list[i] = resolvePath(dir, path)
+			}
+		}
+		if list != nil {
+			values[name] = list
+		}
+	}
+	return values, profiles, nil
+}
+
+// profileValues converts the [profiles] table of a configuration file to the flag
+// values of each profile.
+func profileValues(value interface{}, dir string) (map[string]map[string][]string, error) {
+	tables, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("'profiles' must be a table of profiles")
+	}
+	profiles := map[string]map[string][]string{}
+	for _, name := range sortedKeys(tables) {
+		table, ok := tables[name].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the profile '%s' must be a table of settings", name)
+		}
+		values, nested, err := documentValues(table, dir)
+		if err != nil {
+			return nil, fmt.Errorf("in profile '%s': %v", name, err)
+		}
+		if nested != nil || values["profile"] != nil {
+			return nil, fmt.Errorf("the profile '%s' can't define or select other profiles", name)
+		}
+		profiles[name] = values
+	}
+	return profiles, nil
+}
+
+// addOption adds an Ollama option to the values of a layer, through its dedicated
+// flag if it has one.
+func addOption(values map[string][]string, name string, value interface{}) error {
+	list, err := settingValues(value)
+	if err == nil && len(list) > 1 && name != "stop" {
+		err = fmt.Errorf("must be a single value")
+	}
+	if err != nil {
+		return fmt.Errorf("the option '%s' %v", name, err)
+	}
+	if flagName, ok := optionFlags[name]; ok && list != nil {
+		values[flagName] = list
+		return nil
+	}
+	for _, v := range list {
+		values["option"] = append(values["option"], name+"="+v)
+	}
+	return nil
+}
+
+// addPair adds a key/value pair of the flag to the values of a layer.
+func addPair(values map[string][]string, flagName, key string, value interface{}) error {
+	list, err := settingValues(value)
+	if err == nil && len(list) > 1 {
+		err = fmt.Errorf("must be a single value")
+	}
+	if err != nil {
+		return fmt.Errorf("the %s '%s' %v", flagName, key, err)
+	}
+	for _, v := range list {
+		values[flagName] = append(values[flagName], key+pairSeparators[flagName]+v)
+	}
+	return nil
+}
+
+// settingValues converts a value or a list of values of a document to flag values.
+func settingValues(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return nil, fmt.Errorf("must be a value or a list of values")
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case []interface{}, map[string]interface{}:
+				return nil, fmt.Errorf("must be a value or a list of values")
+			}
+			list = append(list, fmt.Sprint(item))
+		}
+		return list, nil
+	default:
+		return []string{fmt.Sprint(v)}, nil
+	}
+}
+
+// resolvePath resolves a relative path from dir.
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// layerSetting is the value of a setting and the layer it comes from.
+type layerSetting struct {
+	values []string
+	source string
+}
+
+// resolveLayers returns the settings of the layers by flag name, each from the
+// layer with the highest precedence that sets it. The key/value pairs of the
+// "option", "var" and "header" flags are merged by key, e.g. as "option.key".
+func resolveLayers(layers []Layer) map[string]layerSetting {
+	settings := map[string]layerSetting{}
+	for _, layer := range layers {
+		pairs := map[string][]string{}
+		for name, values := range layer.Values {
+			separator, ok := pairSeparators[name]
+			if !ok {
+				settings[name] = layerSetting{values: values, source: layer.Source}
+				continue
+			}
+			for _, pair := range values {
+				key, value, _ := strings.Cut(pair, strings.TrimSpace(separator))
+				if name == "header" {
+					value = strings.TrimSpace(value) // As HTTP does
+				}
+				key = name + "." + strings.TrimSpace(key)
+				pairs[key] = append(pairs[key], value)
+			}
+		}
+		for key, values := range pairs {
+			settings[key] = layerSetting{values: values, source: layer.Source}
+		}
+	}
+	return settings
+}
+
+// checkSetting checks that the layer setting is a flag that can be set outside
+// the command line, with a single value unless the flag is repeatable.
+func checkSetting(fs *flag.FlagSet, name string, setting layerSetting) error {
+	f := fs.Lookup(name)
+	if f == nil || shortForm(fs, f) {
+		return fmt.Errorf("unknown setting '%s' in %s", name, setting.source)
+	}
+	if commandLineOnly[name] {
+		return fmt.Errorf("the setting '%s' can only be given on the command line, found in %s", name, setting.source)
+	}
+	if _, repeatable := f.Value.(*arrayFlags); !repeatable && len(setting.values) > 1 {
+		return fmt.Errorf("the setting '%s' in %s must be a single value", name, setting.source)
+	}
+	return nil
+}
+
+// applyLayers sets the flags that weren't given on the command line from the layers.
+func applyLayers(fs *flag.FlagSet, layers []Layer) error {
+	given := givenFlags(fs)
+	settings := resolveLayers(layers)
+	givenOptions := *fs.Lookup("option").Value.(*arrayFlags)
+
+	pairs := map[string][]string{}
+	for _, name := range sortedKeys(settings) {
+		setting := settings[name]
+		flagName, key, isPair := strings.Cut(name, ".")
+		if err := checkSetting(fs, flagName, setting); err != nil {
+			return err
+		}
+		if isPair {
+			for _, value := range setting.values {
+				pairs[flagName] = append(pairs[flagName], key+pairSeparators[flagName]+value)
+			}
+			continue
+		}
+		option := dedicatedOption(flagName)
+		if given[flagName] || groupGiven(given, flagName) || (option != "" && optionGiven(givenOptions, option)) {
+			continue
+		}
+		for _, value := range setting.values {
+			if err := fs.Set(flagName, value); err != nil {
+				return fmt.Errorf("invalid value for %s in %s: %v", flagName, setting.source, err)
+			}
+		}
+	}
+
+	// The pairs of the layers come first, for those of the command line to take precedence
+	for flagName, values := range pairs {
+		list := fs.Lookup(flagName).Value.(*arrayFlags)
+		*list = append(values, *list...)
+	}
+	return nil
+}
+
+// givenFlags returns the flags given on the command line, along with their other
+// forms, which share their value.
+func givenFlags(fs *flag.FlagSet) map[string]bool {
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		fs.VisitAll(func(other *flag.Flag) {
+			if other.Value == f.Value {
+				given[other.Name] = true
+			}
+		})
+	})
+	return given
+}
+
+// shortForm reports whether the flag is the short form of another flag.
+func shortForm(fs *flag.FlagSet, f *flag.Flag) bool {
+	short := false
+	fs.VisitAll(func(other *flag.Flag) {
+		if other.Value == f.Value && len(other.Name) > len(f.Name) {
+			short = true
+		}
+	})
+	return short
+}
+
+// groupGiven reports whether another flag for the same setting was given.
+func groupGiven(given map[string]bool, name string) bool {
+	for _, group := range flagGroups {
+		for _, member := range group {
+			if member != name {
+				continue
+			}
+			for _, other := range group {
+				if given[other] {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// dedicatedOption returns the Ollama option of a dedicated option flag, or "".
+func dedicatedOption(flagName string) string {
+	for option, name := range optionFlags {
+		if name == flagName {
+			return option
+		}
+	}
+	return ""
+}
+
+// sortedKeys returns the keys of the map in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
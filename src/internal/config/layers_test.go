@@ -0,0 +1,193 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// chdir changes the working directory for the duration of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change the working directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestLoadLayers(t *testing.T) {
+	configDir, project := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	for _, env := range envSettings {
+		t.Setenv(env.envVar, "")
+	}
+	t.Setenv("NINO_URL", "http://ci:11434/api/generate")
+
+	userFile := filepath.Join(configDir, "nino", "config.yaml")
+	projectFile := filepath.Join(project, ".nino.toml")
+	if err := os.MkdirAll(filepath.Dir(userFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(userFile, []byte("model: llama3.1\nkeep_alive: 5m\noptions:\n  num_ctx: 8192\n  top_k: 20\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(projectFile, []byte("model = \"codellama\"\nsystem-file = \"prompts/system.md\"\n[vars]\nlang = \"Go\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, project)
+	// Resolve symbolic links in the temporary directory, as Getwd does
+	wd, _ := os.Getwd()
+	projectFile = filepath.Join(wd, ".nino.toml")
+
+	got, err := LoadLayers("")
+	if err != nil {
+		t.Fatalf("LoadLayers() unexpected error: %v", err)
+	}
+	want := []Layer{
+		{Source: "user config " + userFile, Values: map[string][]string{
+			"model":      {"llama3.1"},
+			"keep-alive": {"5m"},
+			"ctx-size":   {"8192"},
+			"option":     {"top_k=20"},
+		}},
+		{Source: "project config " + projectFile, Values: map[string][]string{
+			"model":       {"codellama"},
+			"system-file": {filepath.Join(wd, "prompts", "system.md")},
+			"var":         {"lang=Go"},
+		}},
+		{Source: "env NINO_URL", Values: map[string][]string{"url": {"http://ci:11434/api/generate"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadLayers() = %+v\nwant %+v", got, want)
+	}
+
+	if err := os.WriteFile(projectFile, []byte("model = \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLayers(""); err == nil || !strings.Contains(err.Error(), "error in project config") {
+		t.Errorf("LoadLayers() error = %v, want an error in the project config", err)
+	}
+}
+
+func TestApplyLayers(t *testing.T) {
+	user := func(values map[string][]string) Layer { return Layer{Source: "user config", Values: values} }
+	project := func(values map[string][]string) Layer { return Layer{Source: "project config", Values: values} }
+	env := func(name, value string) Layer {
+		return Layer{Source: "env", Values: map[string][]string{name: {value}}}
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		layers      []Layer
+		wantModel   string
+		wantSystem  string
+		wantOptions map[string]interface{}
+		wantErr     string
+	}{
+		{
+			name: "Higher layers take precedence",
+			layers: []Layer{
+				user(map[string][]string{"model": {"llama3.1"}, "system": {"Be brief."}}),
+				project(map[string][]string{"model": {"codellama"}}),
+				env("model", "mistral"),
+			},
+			wantModel:  "mistral",
+			wantSystem: "Be brief.",
+		},
+		{
+			name:       "Flags take precedence over the layers",
+			args:       []string{"-m", "phi3", "-system", "Be kind."},
+			layers:     []Layer{env("model", "mistral"), env("system", "Be brief.")},
+			wantModel:  "phi3",
+			wantSystem: "Be kind.",
+		},
+		{
+			name:      "A flag for the same setting overrides the layers",
+			args:      []string{"-no-system"},
+			layers:    []Layer{user(map[string][]string{"system": {"Be brief."}})},
+			wantModel: "llama3.2",
+		},
+		{
+			name: "Option pairs are merged by key",
+			args: []string{"-option", "num_gpu=2"},
+			layers: []Layer{
+				user(map[string][]string{"option": {"top_k=20", "num_gpu=1"}, "stop": {"a", "b"}}),
+				project(map[string][]string{"option": {"top_k=40"}, "stop": {"c"}}),
+			},
+			wantModel:   "llama3.2",
+			wantOptions: map[string]interface{}{"top_k": 40, "num_gpu": 2, "stop": []string{"c"}},
+		},
+		{
+			name:        "An option given with -option overrides its dedicated flag",
+			args:        []string{"-option", "temperature=0.9"},
+			layers:      []Layer{env("temperature", "0.2")},
+			wantModel:   "llama3.2",
+			wantOptions: map[string]interface{}{"temperature": 0.9},
+		},
+		{
+			name:    "Unknown setting",
+			layers:  []Layer{user(map[string][]string{"modle": {"x"}})},
+			wantErr: "unknown setting 'modle' in user config",
+		},
+		{
+			name:    "Short form",
+			layers:  []Layer{user(map[string][]string{"m": {"x"}})},
+			wantErr: "unknown setting 'm' in user config",
+		},
+		{
+			name:    "Command-line only setting",
+			layers:  []Layer{project(map[string][]string{"prompt": {"Hello"}})},
+			wantErr: "the setting 'prompt' can only be given on the command line, found in project config",
+		},
+		{
+			name:    "Several values for a single setting",
+			layers:  []Layer{user(map[string][]string{"model": {"a", "b"}})},
+			wantErr: "the setting 'model' in user config must be a single value",
+		},
+		{
+			name:    "Invalid value",
+			layers:  []Layer{env("temperature", "warm")},
+			wantErr: "invalid value for temperature in env: invalid number 'warm'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			v := defineFlags(fs)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			err := applyLayers(fs, tt.layers)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("applyLayers() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyLayers() unexpected error: %v", err)
+			}
+
+			if *v.model != tt.wantModel {
+				t.Errorf("model = %q, want %q", *v.model, tt.wantModel)
+			}
+			if *v.system != tt.wantSystem {
+				t.Errorf("system = %q, want %q", *v.system, tt.wantSystem)
+			}
+			options, err := v.generation.buildOptions()
+			if err != nil {
+				t.Fatalf("buildOptions() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(options, tt.wantOptions) {
+				t.Errorf("options = %v, want %v", options, tt.wantOptions)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// optionalFloat is a float flag that records whether it was set.
+type optionalFloat struct {
+	value float64
+	set   bool
+}
+
+func (f *optionalFloat) String() string {
+	if f == nil || !f.set {
+		return ""
+	}
+	return strconv.FormatFloat(f.value, 'g', -1, 64)
+}
+
+func (f *optionalFloat) Set(value string) error {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number '%s'", value)
+	}
+	f.value, f.set = v, true
+	return nil
+}
+
+// optionalInt is an integer flag that records whether it was set.
+type optionalInt struct {
+	value int
+	set   bool
+}
+
+func (i *optionalInt) String() string {
+	if i == nil || !i.set {
+		return ""
+	}
+	return strconv.Itoa(i.value)
+}
+
+func (i *optionalInt) Set(value string) error {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid integer '%s'", value)
+	}
+	i.value, i.set = v, true
+	return nil
+}
+
+// byteSize is a size flag in bytes that accepts the K, M and G binary suffixes (e.g. 512K).
+type byteSize int64
+
+func (b *byteSize) String() string {
+	if b == nil {
+		return "0"
+	}
+	return strconv.FormatInt(int64(*b), 10)
+}
+
+func (b *byteSize) Set(value string) error {
+	number, multiplier := strings.ToUpper(strings.TrimSpace(value)), int64(1)
+	number = strings.TrimSuffix(strings.TrimSuffix(number, "B"), "I")
+	for i, suffix := range []string{"K", "M", "G"} {
+		if strings.HasSuffix(number, suffix) {
+			number, multiplier = strings.TrimSuffix(number, suffix), int64(1)<<(10*(i+1))
+			break
+		}
+	}
+	v, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || v < 0 {
+		return fmt.Errorf("invalid size '%s'", value)
+	}
+	*b = byteSize(v * multiplier)
+	return nil
+}
+
+// parseOptionValue converts a -option value to the JSON type Ollama expects.
+func parseOptionValue(value string) interface{} {
+	if v, err := strconv.Atoi(value); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseFloat(value, 64); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseBool(value); err == nil {
+		return v
+	}
+	return value
+}
+
+// generationFlags holds the flags mapped to Ollama's generation options.
+type generationFlags struct {
+	temperature optionalFloat
+	seed        optionalInt
+	maxTokens   optionalInt
+	ctxSize     optionalInt
+	stop        arrayFlags
+	options     arrayFlags
+}
+
+// buildOptions merges the generic -option key=value pairs with the dedicated flags,
+// which take precedence. It returns nil when no option is set.
+func (g *generationFlags) buildOptions() (map[string]interface{}, error) {
+	options := make(map[string]interface{})
+	stop := []string{}
+
+	for _, option := range g.options {
+		key, value, ok := strings.Cut(option, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("the -option flag must be in the form key=value, got '%s'", option)
+		}
+		if key == "stop" {
+			stop = append(stop, value)
+			continue
+		}
+		options[key] = parseOptionValue(value)
+	}
+
+	if g.temperature.set {
+		options["temperature"] = g.temperature.value
+	}
+	if g.seed.set {
+		options["seed"] = g.seed.value
+	}
+	if g.maxTokens.set {
+		options["num_predict"] = g.maxTokens.value
+	}
+	if g.ctxSize.set {
+		options["num_ctx"] = g.ctxSize.value
+	}
+	stop = append(stop, g.stop...)
+	if len(stop) > 0 {
+		options["stop"] = stop
+	}
+
+	if len(options) == 0 {
+		return nil, nil
+	}
+	return options, nil
+}
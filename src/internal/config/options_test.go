@@ -0,0 +1,142 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOptionValue(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{"40", 40},
+		{"-1", -1},
+		{"0.95", 0.95},
+		{"1e-3", 0.001},
+		{"true", true},
+		{"false", false},
+		{"mirostat", "mirostat"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := parseOptionValue(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseOptionValue(%q) = %#v; want %#v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestBuildOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(g *generationFlags)
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:  "No options",
+			setup: func(g *generationFlags) {},
+			want:  nil,
+		},
+		{
+			name: "Dedicated flags",
+			setup: func(g *generationFlags) {
+				g.temperature.Set("0")
+				g.seed.Set("42")
+				g.maxTokens.Set("128")
+				g.ctxSize.Set("8192")
+				g.stop.Set("END")
+			},
+			want: map[string]interface{}{
+				"temperature": 0.0,
+				"seed":        42,
+				"num_predict": 128,
+				"num_ctx":     8192,
+				"stop":        []string{"END"},
+			},
+		},
+		{
+			name: "Generic options are overridden by dedicated flags",
+			setup: func(g *generationFlags) {
+				g.options.Set("top_k=40")
+				g.options.Set("temperature=1.5")
+				g.options.Set("stop=###")
+				g.temperature.Set("0.2")
+				g.stop.Set("END")
+			},
+			want: map[string]interface{}{
+				"top_k":       40,
+				"temperature": 0.2,
+				"stop":        []string{"###", "END"},
+			},
+		},
+		{
+			name:    "Option without value",
+			setup:   func(g *generationFlags) { g.options.Set("top_k") },
+			wantErr: true,
+		},
+		{
+			name:    "Option without key",
+			setup:   func(g *generationFlags) { g.options.Set("=40") },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var g generationFlags
+			tt.setup(&g)
+
+			got, err := g.buildOptions()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptionalFlagsRejectInvalidValues(t *testing.T) {
+	var f optionalFloat
+	if err := f.Set("warm"); err == nil || f.set {
+		t.Errorf("Expected optionalFloat to reject 'warm', got err=%v set=%v", err, f.set)
+	}
+
+	var i optionalInt
+	if err := i.Set("4.2"); err == nil || i.set {
+		t.Errorf("Expected optionalInt to reject '4.2', got err=%v set=%v", err, i.set)
+	}
+}
+
+func TestByteSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    byteSize
+		wantErr bool
+	}{
+		{"1024", 1024, false},
+		{"512K", 512 << 10, false},
+		{"512kb", 512 << 10, false},
+		{"2MiB", 2 << 20, false},
+		{"1G", 1 << 30, false},
+		{"0", 0, false},
+		{"-1", 0, true},
+		{"1.5M", 0, true},
+		{"lots", 0, true},
+	}
+
+	for _, tt := range tests {
+		var b byteSize
+		err := b.Set(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("byteSize.Set(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && b != tt.want {
+			t.Errorf("byteSize.Set(%q) = %d, want %d", tt.value, b, tt.want)
+		}
+	}
+}
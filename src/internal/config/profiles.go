@@ -0,0 +1,158 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lucianoayres/nino-cli/internal/fileutil"
+)
+
+// validProfileName matches the names of the profiles that can be selected.
+var validProfileName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// Profile describes a profile defined by the configuration files.
+type Profile struct {
+	Name    string
+	Sources []string            // The sources of the configuration files defining it
+	Values  map[string][]string // Its settings by flag name
+}
+
+// profileLayer returns the settings of the named profile as a layer. A profile
+// defined by several configuration files is merged, the project one taking
+// precedence over the user one.
+func profileLayer(layers []Layer, name string) (Layer, error) {
+	profile := Layer{Source: "profile " + name}
+	var merged []Layer
+	for _, layer := range layers {
+		if values, ok := layer.Profiles[name]; ok {
+			merged = append(merged, Layer{Source: profile.Source, Values: values})
+		}
+	}
+	if len(merged) == 0 {
+		return Layer{}, fmt.Errorf("unknown profile '%s'", name)
+	}
+
+	// Merge the profiles like layers, keeping the pairs by key
+	profile.Values = map[string][]string{}
+	for key, setting := range resolveLayers(merged) {
+		flagName, pairKey, isPair := strings.Cut(key, ".")
+		if !isPair {
+			profile.Values[key] = setting.values
+			continue
+		}
+		for _, value := range setting.values {
+			profile.Values[flagName] = append(profile.Values[flagName], pairKey+pairSeparators[flagName]+value)
+		}
+	}
+	return profile, nil
+}
+
+// Profiles returns the profiles defined by the configuration files sorted by name,
+// and the name of the profile selected by the environment or the configuration files.
+func Profiles() ([]Profile, string, error) {
+	layers, err := loadBaseLayers()
+	if err != nil {
+		return nil, "", err
+	}
+
+	byName := map[string]*Profile{}
+	for _, layer := range layers {
+		for name, values := range layer.Profiles {
+			profile, ok := byName[name]
+			if !ok {
+				profile = &Profile{Name: name, Values: map[string][]string{}}
+				byName[name] = profile
+			}
+			profile.Sources = append(profile.Sources, layer.Source)
+			for key, value := range values {
+				profile.Values[key] = value
+			}
+		}
+	}
+
+	profiles := make([]Profile, 0, len(byName))
+	for _, name := range sortedKeys(byName) {
+		profiles = append(profiles, *byName[name])
+	}
+	selected := ""
+	if setting, ok := resolveLayers(layers)["profile"]; ok && len(setting.values) > 0 {
+		selected = setting.values[len(setting.values)-1]
+	}
+	return profiles, selected, nil
+}
+
+// SelectProfile makes the named profile the default one, by setting the profile
+// key of the user configuration file. It returns the path of the file.
+func SelectProfile(name string) (string, error) {
+	if !validProfileName.MatchString(name) {
+		return "", fmt.Errorf("invalid profile name '%s': use letters, digits, '.', '-' and '_' only", name)
+	}
+	profiles, _, err := Profiles()
+	if err != nil {
+		return "", err
+	}
+	found := false
+	for _, profile := range profiles {
+		found = found || profile.Name == name
+	}
+	if !found {
+		return "", fmt.Errorf("unknown profile '%s'", name)
+	}
+
+	path, err := UserConfigFile()
+	if err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("error reading user config '%s': %v", path, err)
+	}
+	yaml := strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")
+	updated := setTopLevelKey(string(content), "profile", strconv.Quote(name), yaml)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory '%s': %v", filepath.Dir(path), err)
+	}
+	if err := fileutil.WriteFileAtomic(path, []byte(updated), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// setTopLevelKey sets a key at the top level of a TOML or YAML document, replacing
+// its line if it is already set. In TOML, new keys are added before the first table.
+func setTopLevelKey(document, key, value string, yaml bool) string {
+	line := key + " = " + value
+	pattern := regexp.MustCompile(`^` + regexp.QuoteMeta(key) + `\s*=`)
+	if yaml {
+		line = key + ": " + value
+		pattern = regexp.MustCompile(`^` + regexp.QuoteMeta(key) + `\s*:`)
+	}
+
+	lines := strings.SplitAfter(document, "\n")
+	insertAt := len(lines)
+	for i, text := range lines {
+		if !yaml && strings.HasPrefix(strings.TrimSpace(text), "[") {
+			insertAt = i
+			break
+		}
+		if pattern.MatchString(strings.TrimLeft(text, " \t")) && (!yaml || !strings.HasPrefix(text, " ")) {
+			lines[i] = line + "\n"
+			return strings.Join(lines, "")
+		}
+	}
+
+	if insertAt == len(lines) {
+		if document != "" && !strings.HasSuffix(document, "\n") {
+			line = "\n" + line
+		}
+		return document + line + "\n"
+	}
+	lines = append(lines[:insertAt], append([]string{line + "\n\n"}, lines[insertAt:]...)...)
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProfileLayer(t *testing.T) {
+	layers := []Layer{
+		{Source: "user config", Profiles: map[string]map[string][]string{
+			"gpu":   {"url": {"http://gpu:11434"}, "model": {"llama3.1:70b"}, "header": {"Authorization: Bearer a", "X-Team: docs"}},
+			"local": {"model": {"llama3.2"}},
+		}},
+		{Source: "project config", Profiles: map[string]map[string][]string{
+			"gpu": {"model": {"codellama:34b"}, "header": {"Authorization: Bearer b"}},
+		}},
+	}
+
+	got, err := profileLayer(layers, "gpu")
+	if err != nil {
+		t.Fatalf("profileLayer() unexpected error: %v", err)
+	}
+	want := Layer{Source: "profile gpu", Values: map[string][]string{
+		"url":    {"http://gpu:11434"},
+		"model":  {"codellama:34b"},
+		"header": {"Authorization: Bearer b", "X-Team: docs"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("profileLayer() = %+v, want %+v", got, want)
+	}
+
+	if _, err := profileLayer(layers, "cpu"); err == nil || err.Error() != "unknown profile 'cpu'" {
+		t.Errorf("profileLayer() error = %v, want unknown profile 'cpu'", err)
+	}
+}
+
+func TestLoadLayers_Profile(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	for _, env := range envSettings {
+		t.Setenv(env.envVar, "")
+	}
+	chdir(t, t.TempDir())
+
+	userFile := filepath.Join(configDir, "nino", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(userFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := `model = "llama3.2"
+profile = "gpu"
+
+[profiles.gpu]
+url = "http://gpu:11434/api/generate"
+model = "llama3.1:70b"
+keep_alive = "2h"
+headers = { Authorization = "Bearer secret" }
+options = { temperature = 0.2, top_k = 20 }
+
+[profiles.local]
+model = "phi3"
+`
+	if err := os.WriteFile(userFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NINO_MODEL", "mistral")
+	t.Setenv("NINO_URL", "http://ci:11434/api/generate")
+
+	tests := []struct {
+		name      string
+		profile   string
+		env       string
+		args      []string
+		wantModel string
+		wantURL   string
+		wantErr   string
+	}{
+		{
+			name:      "Profile selected by the configuration file beats the environment",
+			wantModel: "llama3.1:70b",
+			wantURL:   "http://gpu:11434/api/generate",
+		},
+		{
+			name:      "Profile selected by the environment",
+			env:       "local",
+			wantModel: "phi3",
+			wantURL:   "http://ci:11434/api/generate",
+		},
+		{
+			name:      "Profile selected by the flag",
+			env:       "local",
+			profile:   "gpu",
+			wantModel: "llama3.1:70b",
+			wantURL:   "http://gpu:11434/api/generate",
+		},
+		{
+			name:      "Flags take precedence over the profile",
+			args:      []string{"-m", "qwen2.5"},
+			wantModel: "qwen2.5",
+			wantURL:   "http://gpu:11434/api/generate",
+		},
+		{
+			name:    "Unknown profile",
+			profile: "cpu",
+			wantErr: "unknown profile 'cpu'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NINO_PROFILE", tt.env)
+
+			layers, err := LoadLayers(tt.profile)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("LoadLayers() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadLayers() unexpected error: %v", err)
+			}
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			v := defineFlags(fs)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			if err := applyLayers(fs, layers); err != nil {
+				t.Fatalf("applyLayers() unexpected error: %v", err)
+			}
+			if *v.model != tt.wantModel {
+				t.Errorf("model = %q, want %q", *v.model, tt.wantModel)
+			}
+			if *v.url != tt.wantURL {
+				t.Errorf("url = %q, want %q", *v.url, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestSetTopLevelKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		yaml     bool
+		want     string
+	}{
+		{
+			name:     "Empty TOML document",
+			document: "",
+			want:     "profile = \"gpu\"\n",
+		},
+		{
+			name:     "TOML key replaced",
+			document: "model = \"a\"\nprofile = \"local\"\n[profiles.gpu]\nmodel = \"b\"\n",
+			want:     "model = \"a\"\nprofile = \"gpu\"\n[profiles.gpu]\nmodel = \"b\"\n",
+		},
+		{
+			name:     "TOML key added before the first table",
+			document: "model = \"a\"\n\n[profiles.gpu]\nprofile_note = 1\n",
+			want:     "model = \"a\"\n\nprofile = \"gpu\"\n\n[profiles.gpu]\nprofile_note = 1\n",
+		},
+		{
+			name:     "TOML key appended without a final newline",
+			document: "model = \"a\"",
+			want:     "model = \"a\"\nprofile = \"gpu\"\n",
+		},
+		{
+			name:     "YAML key replaced at the top level only",
+			document: "profiles:\n  gpu:\n    profile: x\nprofile: local\n",
+			yaml:     true,
+			want:     "profiles:\n  gpu:\n    profile: x\nprofile: \"gpu\"\n",
+		},
+		{
+			name:     "YAML key appended",
+			document: "model: a\n",
+			yaml:     true,
+			want:     "model: a\nprofile: \"gpu\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setTopLevelKey(tt.document, "profile", `"gpu"`, tt.yaml); got != tt.want {
+				t.Errorf("setTopLevelKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectProfile(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	t.Setenv("NINO_PROFILE", "")
+	chdir(t, t.TempDir())
+
+	userFile := filepath.Join(configDir, "nino", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(userFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(userFile, []byte("[profiles.gpu]\nmodel = \"a\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := SelectProfile("cpu"); err == nil || err.Error() != "unknown profile 'cpu'" {
+		t.Errorf("SelectProfile() error = %v, want unknown profile 'cpu'", err)
+	}
+	path, err := SelectProfile("gpu")
+	if err != nil {
+		t.Fatalf("SelectProfile() unexpected error: %v", err)
+	}
+	if path != userFile {
+		t.Errorf("SelectProfile() path = %q, want %q", path, userFile)
+	}
+
+	profiles, selected, err := Profiles()
+	if err != nil {
+		t.Fatalf("Profiles() unexpected error: %v", err)
+	}
+	if selected != "gpu" || len(profiles) != 1 || profiles[0].Name != "gpu" {
+		t.Errorf("Profiles() = %+v, %q, want the gpu profile selected", profiles, selected)
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lucianoayres/nino-cli/internal/files"
+)
+
+// Reference is an @path reference found in the prompt.
+type Reference struct {
+	Token    string // The reference as written, e.g. @main.go:10-20
+	Path     string // The referenced file, empty when unresolved
+	Start    int    // First line included, 0 when the whole file is included
+	End      int    // Last line included, 0 when the whole file is included
+	Resolved bool   // The reference matched a file and was expanded
+}
+
+// lineRangePattern matches a reference with a line range: path:10, path:10-40 or path:10-
+var lineRangePattern = regexp.MustCompile(`^(.+):(\d+)(-(\d*))?$`)
+
+// referenceTrailers are characters that end a sentence rather than a path, e.g. "see @main.go."
+const referenceTrailers = `.,;:!?)]}>"'`
+
+// expandReferences replaces each @path reference in the prompt with the contents
+// of the file, or the given lines with @path:10-40. A reference starts a word; "\@"
+// is a literal "@" and references that don't match a file are left as written.
+func expandReferences(prompt string) (string, []Reference, error) {
+	var b strings.Builder
+	var references []Reference
+	for i := 0; i < len(prompt); {
+		if strings.HasPrefix(prompt[i:], `\@`) {
+			b.WriteByte('@')
+			i += 2
+			continue
+		}
+		if prompt[i] != '@' || (i > 0 && !strings.ContainsRune(" \t\r\n(\"'[{<", rune(prompt[i-1]))) {
+			b.WriteByte(prompt[i])
+			i++
+			continue
+		}
+
+		end := i + 1
+		for end < len(prompt) && !strings.ContainsRune(" \t\r\n", rune(prompt[end])) {
+			end++
+		}
+		if end == i+1 {
+			b.WriteByte('@')
+			i++
+			continue
+		}
+
+		ref, expansion, length, err := resolveReference(prompt[i+1 : end])
+		if err != nil {
+			return "", nil, err
+		}
+		references = append(references, ref)
+		if !ref.Resolved {
+			b.WriteString(prompt[i:end])
+			i = end
+			continue
+		}
+		b.WriteString("\n" + expansion + "\n")
+		i += 1 + length
+	}
+	return b.String(), references, nil
+}
+
+// resolveReference finds the file referenced by the word following an "@",
+// dropping any trailing punctuation. It returns the expansion and the length of
+// the word it used.
+func resolveReference(word string) (Reference, string, int, error) {
+	for candidate := word; candidate != ""; candidate = candidate[:len(candidate)-1] {
+		if expansion, ref, ok, err := readReference(candidate); err != nil || ok {
+			return ref, expansion, len(candidate), err
+		}
+		if !strings.ContainsRune(referenceTrailers, rune(candidate[len(candidate)-1])) {
+			break
+		}
+	}
+	return Reference{Token: "@" + word}, "", 0, nil
+}
+
+// readReference reads the file of a reference candidate, returning false when it doesn't match a file.
+func readReference(candidate string) (string, Reference, bool, error) {
+	ref := Reference{Token: "@" + candidate, Path: candidate}
+	if info, err := os.Stat(candidate); err != nil || info.IsDir() {
+		m := lineRangePattern.FindStringSubmatch(candidate)
+		if m == nil {
+			return "", Reference{}, false, nil
+		}
+		if info, err := os.Stat(m[1]); err != nil || info.IsDir() {
+			return "", Reference{}, false, nil
+		}
+		ref.Path = m[1]
+		ref.Start, _ = strconv.Atoi(m[2])
+		ref.End = ref.Start
+		if m[3] != "" {
+			ref.End, _ = strconv.Atoi(m[4]) // An open range ends at the last line
+		}
+		if ref.Start < 1 || (m[4] != "" && ref.End < ref.Start) {
+			return "", Reference{}, false, fmt.Errorf("invalid line range in reference '%s'", ref.Token)
+		}
+	}
+
+	content, err := os.ReadFile(ref.Path)
+	if err != nil {
+		return "", Reference{}, false, fmt.Errorf("error reading referenced file '%s': %v", ref.Path, err)
+	}
+	if files.IsBinary(content) {
+		return "", Reference{}, false, fmt.Errorf("the referenced file '%s' is not a text file", ref.Path)
+	}
+
+	note := ""
+	if ref.Start > 0 {
+		lines := strings.SplitAfter(string(content), "\n")
+		if lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+		if ref.Start > len(lines) {
+			return "", Reference{}, false, fmt.Errorf("reference '%s' starts after the end of the file (%d lines)", ref.Token, len(lines))
+		}
+		if ref.End == 0 || ref.End > len(lines) {
+			ref.End = len(lines)
+		}
+		content = []byte(strings.Join(lines[ref.Start-1:ref.End], ""))
+		note = fmt.Sprintf("lines %d-%d", ref.Start, ref.End)
+	}
+	ref.Resolved = true
+	return files.Format(ref.Path, note, content), ref, true, nil
+}
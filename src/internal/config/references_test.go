@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandReferences(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to create temporary file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "logo.png"), []byte("\x89PNG\x00"), 0644); err != nil {
+		t.Fatalf("Failed to create temporary file: %v", err)
+	}
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change the working directory: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	tests := []struct {
+		name     string
+		prompt   string
+		want     string
+		wantRefs []Reference
+		wantErr  bool
+	}{
+		{
+			name:     "Whole file",
+			prompt:   "Refactor @main.go to use context",
+			want:     "Refactor \nFile: main.go\n```go\npackage main\n\nfunc main() {}\n```\n to use context",
+			wantRefs: []Reference{{Token: "@main.go", Path: "main.go", Resolved: true}},
+		},
+		{
+			name:     "Line range and trailing punctuation",
+			prompt:   "Explain @main.go:3-10.",
+			want:     "Explain \nFile: main.go (lines 3-3)\n```go\nfunc main() {}\n```\n.",
+			wantRefs: []Reference{{Token: "@main.go:3-10", Path: "main.go", Start: 3, End: 3, Resolved: true}},
+		},
+		{
+			name:     "Single line",
+			prompt:   "@main.go:1",
+			want:     "\nFile: main.go (lines 1-1)\n```go\npackage main\n```\n",
+			wantRefs: []Reference{{Token: "@main.go:1", Path: "main.go", Start: 1, End: 1, Resolved: true}},
+		},
+		{
+			name:   "Escaped and embedded at signs",
+			prompt: `Email me@example.com about \@main.go`,
+			want:   "Email me@example.com about @main.go",
+		},
+		{
+			name:     "Unresolved reference",
+			prompt:   "Ask @team about it",
+			want:     "Ask @team about it",
+			wantRefs: []Reference{{Token: "@team"}},
+		},
+		{
+			name:    "Invalid line range",
+			prompt:  "@main.go:10-2",
+			wantErr: true,
+		},
+		{
+			name:    "Range after the end of the file",
+			prompt:  "@main.go:40-50",
+			wantErr: true,
+		},
+		{
+			name:    "Binary file",
+			prompt:  "@logo.png",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, refs, err := expandReferences(tt.prompt)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expandReferences(%q) expected error but got none", tt.prompt)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandReferences(%q) unexpected error: %v", tt.prompt, err)
+			}
+			if got != tt.want {
+				t.Errorf("expandReferences(%q) = %q, want %q", tt.prompt, got, tt.want)
+			}
+			if !reflect.DeepEqual(refs, tt.wantRefs) {
+				t.Errorf("expandReferences(%q) references = %+v, want %+v", tt.prompt, refs, tt.wantRefs)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"flag"
+	"slices"
+	"strings"
+)
+
+// Setting is a resolved configuration value and where it comes from.
+type Setting struct {
+	Name   string
+	Value  string
+	Source string
+}
+
+// mainSettings are the settings always reported, even with their default value
+var mainSettings = []string{"model", "url", "keep-alive", "system", "temperature", "seed", "max-tokens", "ctx-size"}
+
+// Settings returns the value and source of the main settings and of the settings
+// defined by the configuration files and the environment. Values in overrides,
+// keyed by setting name, take precedence and are reported as flags.
+func Settings(overrides map[string]string) ([]Setting, error) {
+	layers, err := LoadLayers(overrides["profile"])
+	if err != nil {
+		return nil, err
+	}
+	fs := flag.NewFlagSet("settings", flag.ContinueOnError)
+	defineFlags(fs)
+
+	resolved := resolveLayers(layers)
+	names := slices.Clone(mainSettings)
+	for _, name := range sortedKeys(resolved) {
+		flagName, _, _ := strings.Cut(name, ".")
+		if err := checkSetting(fs, flagName, resolved[name]); err != nil {
+			return nil, err
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, name := range sortedKeys(overrides) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	settings := make([]Setting, 0, len(names))
+	for _, name := range names {
+		setting := Setting{Name: name, Source: "default"}
+		if f := fs.Lookup(name); f != nil {
+			setting.Value = f.DefValue
+		}
+		if value, ok := overrides[name]; ok {
+			setting.Value, setting.Source = value, "flag -"+name
+		} else if layer, ok := resolved[name]; ok {
+			setting.Value, setting.Source = strings.Join(layer.values, ", "), layer.source
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSettings(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	if err := os.MkdirAll(filepath.Join(configDir, "nino"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	userFile := filepath.Join(configDir, "nino", "config.toml")
+	content := "model = \"llama3.1\"\nurl = \"http://gpu:11434/api/generate\"\n[options]\ntop_k = 20\n"
+	if err := os.WriteFile(userFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, t.TempDir())
+
+	for _, env := range envSettings {
+		t.Setenv(env.envVar, "")
+	}
+	t.Setenv("NINO_MODEL", "mistral")
+	t.Setenv("NINO_TEMPERATURE", "0.2")
+
+	got, err := Settings(map[string]string{"temperature": "0.7"})
+	if err != nil {
+		t.Fatalf("Settings() unexpected error: %v", err)
+	}
+	want := []Setting{
+		{Name: "model", Value: "mistral", Source: "env NINO_MODEL"},
+		{Name: "url", Value: "http://gpu:11434/api/generate", Source: "user config " + userFile},
+		{Name: "keep-alive", Value: "60m", Source: "default"},
+		{Name: "system", Value: "", Source: "default"},
+		{Name: "temperature", Value: "0.7", Source: "flag -temperature"},
+		{Name: "seed", Value: "", Source: "default"},
+		{Name: "max-tokens", Value: "", Source: "default"},
+		{Name: "ctx-size", Value: "", Source: "default"},
+		{Name: "option.top_k", Value: "20", Source: "user config " + userFile},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Settings() = %+v\nwant %+v", got, want)
+	}
+
+	if err := os.WriteFile(userFile, []byte("modle = \"x\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Settings(nil); err == nil {
+		t.Error("Settings() expected an error for an unknown setting")
+	}
+}
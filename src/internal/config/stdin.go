@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// MaxStdinBytes is the largest input accepted from the standard input
+const MaxStdinBytes = 8 << 20 // 8 MiB
+
+// stdin is the standard input, replaceable in tests
+var stdin io.Reader = os.Stdin
+
+// stdinIsPiped reports whether the standard input is a pipe or a redirected file
+// rather than a terminal, replaceable in tests
+var stdinIsPiped = func() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
+// readStdin reads the whole standard input, failing if it exceeds MaxStdinBytes.
+func readStdin() (string, error) {
+	content, err := io.ReadAll(io.LimitReader(stdin, MaxStdinBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("error reading from stdin: %v", err)
+	}
+	if len(content) > MaxStdinBytes {
+		return "", fmt.Errorf("the input from stdin exceeds the limit of %d bytes", MaxStdinBytes)
+	}
+	return string(content), nil
+}
+
+// combinePrompt appends the piped input to the instruction prompt, enclosed in
+// delimiters so the model can tell them apart.
+func combinePrompt(instruction, input string) string {
+	input = strings.TrimRight(input, "\r\n")
+	if instruction == "" {
+		return input
+	}
+	return fmt.Sprintf("%s\n\n<input>\n%s\n</input>", strings.TrimRight(instruction, "\r\n"), input)
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCombinePrompt(t *testing.T) {
+	tests := []struct {
+		name        string
+		instruction string
+		input       string
+		want        string
+	}{
+		{"Instruction and input", "Summarize:", "line 1\nline 2\n", "Summarize:\n\n<input>\nline 1\nline 2\n</input>"},
+		{"Input only", "", "line 1\n", "line 1"},
+		{"Instruction with trailing newline", "Summarize:\n", "text", "Summarize:\n\n<input>\ntext\n</input>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := combinePrompt(tt.instruction, tt.input); got != tt.want {
+				t.Errorf("combinePrompt(%q, %q) = %q, want %q", tt.instruction, tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadStdin(t *testing.T) {
+	origStdin := stdin
+	defer func() { stdin = origStdin }()
+
+	stdin = strings.NewReader(strings.Repeat("a", MaxStdinBytes))
+	if got, err := readStdin(); err != nil || len(got) != MaxStdinBytes {
+		t.Errorf("readStdin() at the limit = %d bytes, %v; want %d bytes, nil", len(got), err, MaxStdinBytes)
+	}
+
+	stdin = strings.NewReader(strings.Repeat("a", MaxStdinBytes+1))
+	if _, err := readStdin(); err == nil {
+		t.Error("readStdin() over the limit expected an error but got none")
+	}
+}
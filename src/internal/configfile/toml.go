@@ -0,0 +1,400 @@
+package configfile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TOML documents are parsed with a subset of TOML: key/value pairs with bare,
+// quoted and dotted keys, [tables] and [dotted.tables], basic and literal strings
+// (single and multi-line), integers, floats, booleans, arrays, which may span
+// lines, and inline tables. Dates and arrays of tables are not supported.
+// Integers are resolved to int64.
+
+// tomlParser parses a TOML document.
+type tomlParser struct {
+	src string
+	pos int
+}
+
+// ParseTOML parses a TOML document.
+func ParseTOML(document string) (map[string]interface{}, error) {
+	p := &tomlParser{src: strings.ReplaceAll(document, "\r\n", "\n")}
+	root := map[string]interface{}{}
+	table := root
+	headers := map[string]bool{}
+
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return root, nil
+		}
+
+		if p.peek() == '[' {
+			if strings.HasPrefix(p.src[p.pos:], "[[") {
+				return nil, p.errorf("arrays of tables are not supported")
+			}
+			p.pos++
+			p.skipSpace()
+			keys, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+			if !p.consume("]") {
+				return nil, p.errorf("expected ']' after the table name")
+			}
+			name := strings.Join(keys, ".")
+			if headers[name] {
+				return nil, p.errorf("table [%s] is defined twice", name)
+			}
+			headers[name] = true
+			if table, err = p.subtable(root, keys); err != nil {
+				return nil, err
+			}
+		} else if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+
+		p.skipSpace()
+		if !p.eof() && p.peek() != '\n' && p.peek() != '#' {
+			return nil, p.errorf("expected a new line, found '%c'", p.peek())
+		}
+	}
+}
+
+// parseKeyValue parses a key = value pair into the table.
+func (p *tomlParser) parseKeyValue(table map[string]interface{}) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	if !p.consume("=") {
+		return p.errorf("expected '=' after the key '%s'", strings.Join(keys, "."))
+	}
+	p.skipSpace()
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	parent, err := p.subtable(table, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	key := keys[len(keys)-1]
+	if _, ok := parent[key]; ok {
+		return p.errorf("the key '%s' is defined twice", strings.Join(keys, "."))
+	}
+	parent[key] = value
+	return nil
+}
+
+// subtable returns the table at the path of keys below table, creating the missing ones.
+func (p *tomlParser) subtable(table map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	for i, key := range keys {
+		switch value := table[key].(type) {
+		case nil:
+			child := map[string]interface{}{}
+			table[key] = child
+			table = child
+		case map[string]interface{}:
+			table = value
+		default:
+			return nil, p.errorf("the key '%s' is not a table", strings.Join(keys[:i+1], "."))
+		}
+	}
+	return table, nil
+}
+
+// parseKey parses a bare, quoted or dotted key.
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		var key string
+		var err error
+		switch {
+		case p.eof():
+			return nil, p.errorf("expected a key")
+		case p.peek() == '"':
+			key, err = p.parseBasicString()
+		case p.peek() == '\'':
+			key, err = p.parseLiteralString()
+		default:
+			start := p.pos
+			for !p.eof() && isBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			if p.pos == start {
+				return nil, p.errorf("expected a key, found '%c'", p.peek())
+			}
+			key = p.src[start:p.pos]
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+
+		p.skipSpace()
+		if !p.consume(".") {
+			return keys, nil
+		}
+		p.skipSpace()
+	}
+}
+
+// parseValue parses a string, number, boolean, array or inline table.
+func (p *tomlParser) parseValue() (interface{}, error) {
+	if p.eof() {
+		return nil, p.errorf("expected a value")
+	}
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], `"""`):
+		return p.parseMultilineString(`"""`)
+	case strings.HasPrefix(p.src[p.pos:], "'''"):
+		return p.parseMultilineString("'''")
+	case p.peek() == '"':
+		return p.parseBasicString()
+	case p.peek() == '\'':
+		return p.parseLiteralString()
+	case p.peek() == '[':
+		return p.parseArray()
+	case p.peek() == '{':
+		return p.parseInlineTable()
+	}
+
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\n,]}#", rune(p.peek())) {
+		p.pos++
+	}
+	token := p.src[start:p.pos]
+	switch token {
+	case "":
+		return nil, p.errorf("expected a value")
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if v, err := strconv.ParseInt(token, 0, 64); err == nil {
+		return v, nil
+	}
+	if strings.ContainsAny(token, ".eE") || strings.HasSuffix(token, "inf") || strings.HasSuffix(token, "nan") {
+		if v, err := strconv.ParseFloat(strings.ReplaceAll(token, "_", ""), 64); err == nil {
+			return v, nil
+		}
+	}
+	return nil, p.errorf("invalid value '%s'", token)
+}
+
+// parseBasicString parses a double-quoted string with escapes.
+func (p *tomlParser) parseBasicString() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// parseLiteralString parses a single-quoted string, which has no escapes.
+func (p *tomlParser) parseLiteralString() (string, error) {
+	p.pos++
+	end := strings.IndexAny(p.src[p.pos:], "'\n")
+	if end < 0 || p.src[p.pos+end] == '\n' {
+		return "", p.errorf("unterminated string")
+	}
+	s := p.src[p.pos : p.pos+end]
+	p.pos += end + 1
+	return s, nil
+}
+
+// parseMultilineString parses a string delimited by three double quotes (basic) or
+// three single quotes (literal). A new line right after the opening delimiter is
+// trimmed and, in basic strings, a backslash at the end of a line trims the line
+// break and the leading whitespace of the next line.
+func (p *tomlParser) parseMultilineString(delimiter string) (string, error) {
+	p.pos += len(delimiter)
+	p.consume("\n")
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		if strings.HasPrefix(p.src[p.pos:], delimiter) {
+			p.pos += len(delimiter)
+			// Up to two quotes can precede the closing delimiter
+			for i := 0; i < 2 && p.consume(delimiter[:1]); i++ {
+				b.WriteString(delimiter[:1])
+			}
+			return b.String(), nil
+		}
+		c := p.src[p.pos]
+		if c != '\\' || delimiter == "'''" {
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+		if rest := strings.TrimLeft(p.src[p.pos+1:], " \t"); strings.HasPrefix(rest, "\n") {
+			p.pos = len(p.src) - len(strings.TrimLeft(rest, " \t\n"))
+			continue
+		}
+		if err := p.parseEscape(&b); err != nil {
+			return "", err
+		}
+	}
+}
+
+// parseEscape parses the escape sequence at the current position into b.
+func (p *tomlParser) parseEscape(b *strings.Builder) error {
+	p.pos++
+	if p.eof() {
+		return p.errorf("unterminated string")
+	}
+	c := p.src[p.pos]
+	p.pos++
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case 'e':
+		b.WriteByte('\x1b')
+	case '"', '\\':
+		b.WriteByte(c)
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.src) {
+			return p.errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.src[p.pos:p.pos+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorf("invalid unicode escape '\\%c%s'", c, p.src[p.pos:p.pos+size])
+		}
+		b.WriteRune(rune(code))
+		p.pos += size
+	default:
+		return p.errorf("invalid escape '\\%c'", c)
+	}
+	return nil
+}
+
+// parseArray parses an array, whose values may span lines.
+func (p *tomlParser) parseArray() ([]interface{}, error) {
+	p.pos++
+	values := []interface{}{}
+	for {
+		p.skipBlank()
+		if p.consume("]") {
+			return values, nil
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		p.skipBlank()
+		if !p.consume(",") && (p.eof() || p.peek() != ']') {
+			return nil, p.errorf("expected ',' or ']' in the array")
+		}
+	}
+}
+
+// parseInlineTable parses a table written on one line, such as { a = 1, b = 2 }.
+func (p *tomlParser) parseInlineTable() (map[string]interface{}, error) {
+	p.pos++
+	table := map[string]interface{}{}
+	p.skipSpace()
+	if p.consume("}") {
+		return table, nil
+	}
+	for {
+		p.skipSpace()
+		if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.consume("}") {
+			return table, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected ',' or '}' in the inline table")
+		}
+	}
+}
+
+// skipSpace moves past spaces and tabs.
+func (p *tomlParser) skipSpace() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// skipBlank moves past whitespace, line breaks and comments.
+func (p *tomlParser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\n':
+			p.pos++
+		case '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *tomlParser) peek() byte {
+	return p.src[p.pos]
+}
+
+// consume moves past s if the document continues with it.
+func (p *tomlParser) consume(s string) bool {
+	if strings.HasPrefix(p.src[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// errorf returns an error for the current line.
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.src[:p.pos], "\n") + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
@@ -0,0 +1,118 @@
+package configfile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     map[string]interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "Scalars and comments",
+			document: "# nino\nmodel = \"llama3.2\" # trailing comment\ntemperature = 0.2\nseed = 42\nno-loading = true\nbig = 1_000\nhex = 0xff\n",
+			want:     map[string]interface{}{"model": "llama3.2", "temperature": 0.2, "seed": int64(42), "no-loading": true, "big": int64(1000), "hex": int64(255)},
+		},
+		{
+			name:     "Strings",
+			document: "basic = \"tab\\tquote\\\" \\u00e9\"\nliteral = 'C:\\path'\n\"quoted key\" = 'x'",
+			want:     map[string]interface{}{"basic": "tab\tquote\" é", "literal": `C:\path`, "quoted key": "x"},
+		},
+		{
+			name:     "Multi-line strings",
+			document: "system = \"\"\"\nYou are a release manager.\nBe \\\n    concise.\"\"\"\nraw = '''\nno \\n escapes\n'''",
+			want:     map[string]interface{}{"system": "You are a release manager.\nBe concise.", "raw": "no \\n escapes\n"},
+		},
+		{
+			name:     "Arrays spanning lines",
+			document: "stop = [\n  \"\\n\\n\", # blank line\n  \"END\",\n]\nempty = []",
+			want:     map[string]interface{}{"stop": []interface{}{"\n\n", "END"}, "empty": []interface{}{}},
+		},
+		{
+			name:     "Tables and dotted keys",
+			document: "model = \"a\"\n[options]\ntop_k = 20\n[profiles.gpu]\nurl = \"http://gpu:11434\"\nheaders.Authorization = \"Bearer x\"\n",
+			want: map[string]interface{}{
+				"model":   "a",
+				"options": map[string]interface{}{"top_k": int64(20)},
+				"profiles": map[string]interface{}{
+					"gpu": map[string]interface{}{
+						"url":     "http://gpu:11434",
+						"headers": map[string]interface{}{"Authorization": "Bearer x"},
+					},
+				},
+			},
+		},
+		{
+			name:     "Inline table",
+			document: "vars = { lang = \"Go\", level = 2 }",
+			want:     map[string]interface{}{"vars": map[string]interface{}{"lang": "Go", "level": int64(2)}},
+		},
+		{
+			name:     "Windows line endings",
+			document: "model = \"a\"\r\nseed = 1\r\n",
+			want:     map[string]interface{}{"model": "a", "seed": int64(1)},
+		},
+		{
+			name:     "Duplicate key",
+			document: "model = \"a\"\nmodel = \"b\"",
+			wantErr:  true,
+		},
+		{
+			name:     "Duplicate table",
+			document: "[options]\n[options]",
+			wantErr:  true,
+		},
+		{
+			name:     "Missing value",
+			document: "model =\n",
+			wantErr:  true,
+		},
+		{
+			name:     "Unquoted string",
+			document: "model = llama3.2",
+			wantErr:  true,
+		},
+		{
+			name:     "Two values on a line",
+			document: "model = \"a\" seed = 1",
+			wantErr:  true,
+		},
+		{
+			name:     "Unterminated string",
+			document: "model = \"a\nseed = 1",
+			wantErr:  true,
+		},
+		{
+			name:     "Array of tables",
+			document: "[[profiles]]\nname = \"a\"",
+			wantErr:  true,
+		},
+		{
+			name:     "Key redefined as a table",
+			document: "options = 1\n[options]",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTOML(tt.document)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseTOML() expected error but got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTOML() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTOML() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package configfile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// YAML documents are parsed with a small subset: mappings nested by
+// indentation, sequences of scalars ("- item" lines or [a, b] flow sequences),
+// flow mappings of scalars ({a: 1}), quoted and plain scalars, literal (|) and
+// folded (>) block scalars and comments. Plain scalars are resolved to bool,
+// int, float64, nil or string, as in YAML.
+
+// yamlLine is a line of the document with its indentation.
+type yamlLine struct {
+	number int    // Line number in the document, for errors
+	indent int    // Number of leading spaces
+	text   string // Line without the indentation
+}
+
+// yamlParser parses the lines of a YAML document.
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// ParseYAML parses a YAML document whose root is a mapping. firstLine is the line
+// number of the document in its file, for errors.
+func ParseYAML(document string, firstLine int) (map[string]interface{}, error) {
+	p := &yamlParser{}
+	for i, raw := range strings.Split(strings.ReplaceAll(document, "\r\n", "\n"), "\n") {
+		text := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", firstLine+i)
+		}
+		p.lines = append(p.lines, yamlLine{number: firstLine + i, indent: len(raw) - len(text), text: text})
+	}
+
+	p.skipBlank()
+	if p.pos == len(p.lines) {
+		return map[string]interface{}{}, nil
+	}
+	root, err := p.parseMapping(p.lines[p.pos].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.skipBlank(); p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].number)
+	}
+	return root, nil
+}
+
+// skipBlank moves past blank lines and comments.
+func (p *yamlParser) skipBlank() {
+	for p.pos < len(p.lines) {
+		text := strings.TrimSpace(p.lines[p.pos].text)
+		if text != "" && !strings.HasPrefix(text, "#") {
+			return
+		}
+		p.pos++
+	}
+}
+
+// parseMapping parses the "key: value" lines at the given indentation.
+func (p *yamlParser) parseMapping(indent int) (map[string]interface{}, error) {
+	mapping := map[string]interface{}{}
+	for p.skipBlank(); p.pos < len(p.lines); p.skipBlank() {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.number)
+		}
+
+		key, rest, ok := splitKey(line.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected 'key: value', got '%s'", line.number, line.text)
+		}
+		if _, exists := mapping[key]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key '%s'", line.number, key)
+		}
+		p.pos++
+
+		value, err := p.parseValue(line, rest)
+		if err != nil {
+			return nil, err
+		}
+		mapping[key] = value
+	}
+	return mapping, nil
+}
+
+// parseValue parses the value of a key: inline, as a block scalar or as a nested block.
+func (p *yamlParser) parseValue(line yamlLine, rest string) (interface{}, error) {
+	rest = stripComment(rest)
+	if strings.HasPrefix(rest, "|") || strings.HasPrefix(rest, ">") {
+		return p.parseBlockScalar(line, rest)
+	}
+	if rest != "" {
+		value, err := parseScalar(rest)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line.number, err)
+		}
+		return value, nil
+	}
+
+	// The value is the nested block, if any
+	p.skipBlank()
+	if p.pos == len(p.lines) {
+		return nil, nil
+	}
+	next := p.lines[p.pos]
+	switch {
+	case strings.HasPrefix(next.text, "- ") || next.text == "-":
+		if next.indent < line.indent {
+			return nil, nil
+		}
+		return p.parseSequence(next.indent)
+	case next.indent > line.indent:
+		return p.parseMapping(next.indent)
+	}
+	return nil, nil
+}
+
+// parseSequence parses the "- item" lines at the given indentation.
+func (p *yamlParser) parseSequence(indent int) ([]interface{}, error) {
+	var sequence []interface{}
+	for p.skipBlank(); p.pos < len(p.lines); p.skipBlank() {
+		line := p.lines[p.pos]
+		if line.indent != indent || !(strings.HasPrefix(line.text, "- ") || line.text == "-") {
+			if line.indent > indent {
+				return nil, fmt.Errorf("line %d: unexpected indentation", line.number)
+			}
+			break
+		}
+		p.pos++
+
+		item := stripComment(strings.TrimPrefix(line.text, "-"))
+		if _, _, ok := splitKey(item); ok && !strings.HasPrefix(item, `"`) && !strings.HasPrefix(item, "'") {
+			return nil, fmt.Errorf("line %d: mappings in sequences are not supported", line.number)
+		}
+		value, err := parseScalar(item)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line.number, err)
+		}
+		sequence = append(sequence, value)
+	}
+	return sequence, nil
+}
+
+// parseBlockScalar parses a literal (|) or folded (>) block scalar, with the
+// optional strip (-) or keep (+) chomping indicator.
+func (p *yamlParser) parseBlockScalar(line yamlLine, header string) (string, error) {
+	folded := header[0] == '>'
+	chomping := strings.TrimSpace(header[1:])
+	if chomping != "" && chomping != "-" && chomping != "+" {
+		return "", fmt.Errorf("line %d: unsupported block scalar header '%s'", line.number, header)
+	}
+
+	// The block holds the following lines indented further than the key, and blank lines
+	var lines []string
+	blockIndent := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		next := p.lines[p.pos]
+		if strings.TrimSpace(next.text) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if next.indent <= line.indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = next.indent
+		}
+		if next.indent < blockIndent {
+			return "", fmt.Errorf("line %d: unexpected indentation in block scalar", next.number)
+		}
+		lines = append(lines, strings.Repeat(" ", next.indent-blockIndent)+next.text)
+	}
+
+	// Trailing blank lines are only kept with the keep indicator
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var text string
+	if folded {
+		var b strings.Builder
+		for i, l := range lines {
+			// Blank lines and more indented lines keep their line breaks, the others are joined
+			switch {
+			case i == 0 || (lines[i-1] == "" && l != ""):
+			case l == "" || strings.HasPrefix(l, " ") || strings.HasPrefix(lines[i-1], " "):
+				b.WriteString("\n")
+			default:
+				b.WriteString(" ")
+			}
+			b.WriteString(l)
+		}
+		text = b.String()
+	} else {
+		text = strings.Join(lines, "\n")
+	}
+
+	switch {
+	case text == "":
+		return "", nil
+	case chomping == "-":
+		return text, nil
+	case chomping == "+":
+		return text + strings.Repeat("\n", trailing+1), nil
+	}
+	return text + "\n", nil
+}
+
+// splitKey splits a "key: value" line, returning false when it isn't one.
+func splitKey(text string) (string, string, bool) {
+	var key, rest string
+	if i := strings.Index(text, ": "); i >= 0 {
+		key, rest = text[:i], text[i+2:]
+	} else if strings.HasSuffix(text, ":") {
+		key = text[:len(text)-1]
+	} else {
+		return "", "", false
+	}
+	key = strings.TrimSpace(key)
+	if unquoted, err := parseQuoted(key); err == nil {
+		key = unquoted
+	}
+	if key == "" || strings.ContainsAny(key[:1], "-[{#") {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(rest), true
+}
+
+// stripComment removes a trailing comment, outside of quotes, from an inline value.
+func stripComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' '):
+			return strings.TrimSpace(text[:i])
+		}
+	}
+	return strings.TrimSpace(text)
+}
+
+// parseScalar parses an inline value: a quoted or plain scalar, or a flow
+// sequence or mapping of scalars.
+func parseScalar(text string) (interface{}, error) {
+	text = strings.TrimSpace(text)
+	switch {
+	case text == "":
+		return nil, nil
+	case strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'"):
+		return parseQuoted(text)
+	case strings.HasPrefix(text, "["):
+		if !strings.HasSuffix(text, "]") {
+			return nil, fmt.Errorf("unterminated flow sequence '%s'", text)
+		}
+		items, err := splitFlow(text[1 : len(text)-1])
+		if err != nil {
+			return nil, err
+		}
+		sequence := []interface{}{}
+		for _, item := range items {
+			value, err := parseScalar(item)
+			if err != nil {
+				return nil, err
+			}
+			sequence = append(sequence, value)
+		}
+		return sequence, nil
+	case strings.HasPrefix(text, "{"):
+		if !strings.HasSuffix(text, "}") {
+			return nil, fmt.Errorf("unterminated flow mapping '%s'", text)
+		}
+		items, err := splitFlow(text[1 : len(text)-1])
+		if err != nil {
+			return nil, err
+		}
+		mapping := map[string]interface{}{}
+		for _, item := range items {
+			key, rest, ok := splitKey(item)
+			if !ok {
+				return nil, fmt.Errorf("expected 'key: value' in flow mapping, got '%s'", item)
+			}
+			value, err := parseScalar(rest)
+			if err != nil {
+				return nil, err
+			}
+			mapping[key] = value
+		}
+		return mapping, nil
+	}
+
+	switch text {
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case "null", "Null", "NULL", "~":
+		return nil, nil
+	}
+	if v, err := strconv.Atoi(text); err == nil {
+		return v, nil
+	}
+	if v, err := strconv.ParseFloat(text, 64); err == nil {
+		return v, nil
+	}
+	return text, nil
+}
+
+// parseQuoted parses a double-quoted scalar, with Go escapes, or a single-quoted
+// scalar, where a doubled single quote is a literal quote.
+func parseQuoted(text string) (string, error) {
+	if len(text) >= 2 && text[0] == '\'' && text[len(text)-1] == '\'' {
+		inner := text[1 : len(text)-1]
+		if strings.Contains(strings.ReplaceAll(inner, "''", ""), "'") {
+			return "", fmt.Errorf("invalid single-quoted string %s", text)
+		}
+		return strings.ReplaceAll(inner, "''", "'"), nil
+	}
+	if len(text) >= 2 && text[0] == '"' {
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return "", fmt.Errorf("invalid double-quoted string %s", text)
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("not a quoted string: %s", text)
+}
+
+// splitFlow splits the items of a flow collection on the commas outside of quotes.
+func splitFlow(text string) ([]string, error) {
+	var items []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			return nil, fmt.Errorf("nested flow collections are not supported")
+		case c == ',':
+			items = append(items, strings.TrimSpace(text[start:i]))
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quoted string in '%s'", text)
+	}
+	if last := strings.TrimSpace(text[start:]); last != "" || len(items) > 0 {
+		items = append(items, last)
+	}
+	return items, nil
+}
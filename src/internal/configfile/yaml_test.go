@@ -0,0 +1,89 @@
+package configfile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     map[string]interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "Plain scalars",
+			document: "model: llama3.2\ntemperature: 0.2\nseed: 42\nraw: true\nempty:\nnothing: ~",
+			want:     map[string]interface{}{"model": "llama3.2", "temperature": 0.2, "seed": 42, "raw": true, "empty": nil, "nothing": nil},
+		},
+		{
+			name:     "Quoted scalars and comments",
+			document: "# Recipe\nsystem: \"Answer in French.\\n\" # trailing comment\nstop: 'it''s done'\ntag: a#b",
+			want:     map[string]interface{}{"system": "Answer in French.\n", "stop": "it's done", "tag": "a#b"},
+		},
+		{
+			name:     "Nested mapping",
+			document: "options:\n  temperature: 0.7\n  num_ctx: 8192\nmodel: mistral",
+			want:     map[string]interface{}{"options": map[string]interface{}{"temperature": 0.7, "num_ctx": 8192}, "model": "mistral"},
+		},
+		{
+			name:     "Sequences",
+			document: "images:\n  - a.png\n  - \"b c.png\"\nstop: [\"\\n\\n\", END]\nlist:\n- x",
+			want:     map[string]interface{}{"images": []interface{}{"a.png", "b c.png"}, "stop": []interface{}{"\n\n", "END"}, "list": []interface{}{"x"}},
+		},
+		{
+			name:     "Flow mapping",
+			document: "options: {temperature: 0.1, top_k: 20}",
+			want:     map[string]interface{}{"options": map[string]interface{}{"temperature": 0.1, "top_k": 20}},
+		},
+		{
+			name:     "Literal block scalar",
+			document: "system: |\n  You are a release manager.\n\n  Use bullet points:\n    - short\nmodel: x",
+			want:     map[string]interface{}{"system": "You are a release manager.\n\nUse bullet points:\n  - short\n", "model": "x"},
+		},
+		{
+			name:     "Folded block scalar with strip chomping",
+			document: "system: >-\n  You are\n  concise.\n\n  Always.\n",
+			want:     map[string]interface{}{"system": "You are concise.\nAlways."},
+		},
+		{
+			name:     "Duplicate key",
+			document: "model: a\nmodel: b",
+			wantErr:  true,
+		},
+		{
+			name:     "Bad indentation",
+			document: "model: a\n  seed: 1",
+			wantErr:  true,
+		},
+		{
+			name:     "Not a mapping",
+			document: "just text",
+			wantErr:  true,
+		},
+		{
+			name:     "Tab indentation",
+			document: "options:\n\tseed: 1",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseYAML(tt.document, 1)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseYAML() expected error but got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseYAML() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseYAML() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
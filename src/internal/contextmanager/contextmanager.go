@@ -1,28 +1,42 @@
 package contextmanager
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"time"
 
+	"github.com/lucianoayres/nino-cli/internal/fileutil"
 	"github.com/lucianoayres/nino-cli/internal/logger"
+	"github.com/lucianoayres/nino-cli/internal/utils"
 )
 
-// getDataDir returns the data directory, checking XDG_DATA_HOME first.
-func getDataDir() (string, error) {
-	dataDir := os.Getenv("XDG_DATA_HOME")
-	if dataDir == "" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("unable to determine home directory: %v", err)
-		}
-		dataDir = filepath.Join(homeDir, ".local", "share")
-	}
-	return dataDir, nil
-}
+const (
+	// contextFileName is the file holding the binary encoded context.
+	contextFileName = "context.bin"
+	// legacyContextFileName is the JSON file used by previous versions.
+	legacyContextFileName = "context.json"
+	// lockFileName is the advisory lock file guarding the context files of a model.
+	lockFileName = "context.lock"
+	// contextVersion is the version of the binary context format.
+	contextVersion = 1
+)
+
+// warningOutput receives the warnings shown to the user, e.g. when a corrupt context is quarantined.
+var warningOutput io.Writer = os.Stderr
+
+// contextMagic identifies a binary context file.
+var contextMagic = []byte("NCTX")
+
+// MaxContextTokens caps the number of tokens kept in a saved context.
+// Longer contexts keep only their most recent tokens.
+const MaxContextTokens = 128 * 1024
 
 // sanitizeModelName sanitizes the model name for use in file paths.
 func sanitizeModelName(s string) string {
@@ -32,21 +46,74 @@ func sanitizeModelName(s string) string {
 	return re.ReplaceAllString(s, "_")
 }
 
+// getModelDir returns the directory where the data of the given model is stored.
+func getModelDir(modelName string) (string, error) {
+	dataDir, err := utils.GetNinoDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "models", sanitizeModelName(modelName)), nil
+}
+
+// encodeContext encodes the context as a header followed by one varint per token.
+func encodeContext(context []int) []byte {
+	buf := make([]byte, 0, len(contextMagic)+1+binary.MaxVarintLen64+len(context)*3)
+	buf = append(buf, contextMagic...)
+	buf = append(buf, contextVersion)
+	buf = binary.AppendUvarint(buf, uint64(len(context)))
+	for _, token := range context {
+		buf = binary.AppendVarint(buf, int64(token))
+	}
+	return buf
+}
+
+// decodeContext decodes data produced by encodeContext.
+func decodeContext(data []byte) ([]int, error) {
+	if !bytes.HasPrefix(data, contextMagic) || len(data) < len(contextMagic)+1 {
+		return nil, errors.New("not a context file")
+	}
+	data = data[len(contextMagic):]
+	if version := data[0]; version != contextVersion {
+		return nil, fmt.Errorf("unsupported context format version %d", version)
+	}
+	data = data[1:]
+
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, errors.New("invalid token count")
+	}
+	data = data[n:]
+	// Every token takes at least one byte, which bounds the allocation on corrupt input
+	if count > uint64(len(data)) {
+		return nil, fmt.Errorf("truncated context: expected %d tokens, got at most %d", count, len(data))
+	}
+
+	context := make([]int, count)
+	for i := range context {
+		token, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("truncated context: invalid token at index %d", i)
+		}
+		context[i] = int(token)
+		data = data[n:]
+	}
+	if len(data) != 0 {
+		return nil, fmt.Errorf("unexpected %d trailing byte(s)", len(data))
+	}
+	return context, nil
+}
+
 // SaveContext saves the context data for a given model.
 func SaveContext(modelName string, context []int) error {
 	log := logger.GetLogger(true) // Assuming logger is already initialized in main
 	log.Info("Saving context data for model: %s", modelName)
 
-	dataDir, err := getDataDir()
+	path, err := getModelDir(modelName)
 	if err != nil {
 		log.Error("Failed to get data directory: %v", err)
 		return err
 	}
 
-	modelName = sanitizeModelName(modelName)
-
-	path := filepath.Join(dataDir, "nino", "models", modelName)
-
 	// Create directories if they do not exist
 	log.Info("Creating directories if not exist: %s", path)
 	err = os.MkdirAll(path, 0755)
@@ -55,24 +122,30 @@ func SaveContext(modelName string, context []int) error {
 		return fmt.Errorf("failed to create directories: %v", err)
 	}
 
-	contextFile := filepath.Join(path, "context.json")
+	if len(context) > MaxContextTokens {
+		log.Info("Truncating context from %d to the last %d tokens", len(context), MaxContextTokens)
+		context = context[len(context)-MaxContextTokens:]
+	}
 
-	log.Info("Creating context file: %s", contextFile)
-	file, err := os.Create(contextFile)
+	lock, err := fileutil.LockFile(filepath.Join(path, lockFileName), true)
 	if err != nil {
-		log.Error("Failed to create context file: %v", err)
-		return fmt.Errorf("failed to create context file: %v", err)
+		log.Error("Failed to lock context file: %v", err)
+		return err
 	}
-	defer file.Close()
+	defer lock.Unlock()
 
-	encoder := json.NewEncoder(file)
-	log.Info("Encoding context data to JSON")
-	err = encoder.Encode(context)
+	contextFile := filepath.Join(path, contextFileName)
+
+	log.Info("Writing %d context tokens to file: %s", len(context), contextFile)
+	err = fileutil.WriteFileAtomic(contextFile, encodeContext(context), 0644)
 	if err != nil {
-		log.Error("Failed to encode context data: %v", err)
-		return fmt.Errorf("failed to encode context data: %v", err)
+		log.Error("Failed to write context file: %v", err)
+		return fmt.Errorf("failed to write context file: %v", err)
 	}
 
+	// The legacy JSON file is superseded by the binary one
+	os.Remove(filepath.Join(path, legacyContextFileName))
+
 	log.Info("Context data saved successfully")
 	return nil
 }
@@ -82,17 +155,72 @@ func LoadContext(modelName string) ([]int, error) {
 	log := logger.GetLogger(true) // Assuming logger is already initialized in main
 	log.Info("Loading context data for model: %s", modelName)
 
-	dataDir, err := getDataDir()
+	path, err := getModelDir(modelName)
 	if err != nil {
 		log.Error("Failed to get data directory: %v", err)
 		return nil, err
 	}
 
-	modelName = sanitizeModelName(modelName)
+	// Nothing to load if nothing was saved yet; avoids creating directories on read
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		log.Info("Context directory does not exist: %s", path)
+		return nil, nil
+	}
 
-	path := filepath.Join(dataDir, "nino", "models", modelName, "context.json")
+	lock, err := fileutil.LockFile(filepath.Join(path, lockFileName), false)
+	if err != nil {
+		log.Error("Failed to lock context file: %v", err)
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	contextFile := filepath.Join(path, contextFileName)
+
+	log.Info("Reading context file: %s", contextFile)
+	data, err := os.ReadFile(contextFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return loadLegacyContext(filepath.Join(path, legacyContextFileName))
+	}
+	if err != nil {
+		log.Error("Failed to read context file: %v", err)
+		return nil, fmt.Errorf("failed to read context file: %v", err)
+	}
+
+	context, err := decodeContext(data)
+	if err != nil {
+		log.Error("Failed to decode context data: %v", err)
+		quarantine(contextFile, err)
+		return nil, nil
+	}
+
+	log.Info("Context data loaded successfully (%d tokens)", len(context))
+	return context, nil
+}
+
+// quarantine moves a corrupt context file aside so that subsequent runs start from a
+// clean context, and warns the user about it.
+func quarantine(path string, cause error) {
+	log := logger.GetLogger(true) // Assuming logger is already initialized in main
+
+	corruptPath := fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102T150405"))
+	log.Info("Moving corrupt context file to: %s", corruptPath)
+	if err := os.Rename(path, corruptPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// Already moved aside by a concurrent process
+			return
+		}
+		log.Error("Failed to quarantine corrupt context file: %v", err)
+		fmt.Fprintf(warningOutput, "Warning: the context file '%s' is corrupt (%v) and could not be moved aside: %v\n", path, cause, err)
+		return
+	}
+	fmt.Fprintf(warningOutput, "Warning: the context file was corrupt (%v) and has been moved to '%s'. Continuing without context.\n", cause, corruptPath)
+}
+
+// loadLegacyContext loads a context saved as JSON by previous versions.
+func loadLegacyContext(path string) ([]int, error) {
+	log := logger.GetLogger(true) // Assuming logger is already initialized in main
 
-	log.Info("Opening context file: %s", path)
+	log.Info("Opening legacy context file: %s", path)
 	file, err := os.Open(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -111,9 +239,11 @@ func LoadContext(modelName string) ([]int, error) {
 	err = decoder.Decode(&context)
 	if err != nil {
 		log.Error("Failed to decode context data: %v", err)
-		return nil, fmt.Errorf("failed to decode context data: %v", err)
+		file.Close()
+		quarantine(path, err)
+		return nil, nil
 	}
 
-	log.Info("Context data loaded successfully")
+	log.Info("Legacy context data loaded successfully")
 	return context, nil
 }
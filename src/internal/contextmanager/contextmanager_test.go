@@ -1,8 +1,15 @@
 package contextmanager
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/lucianoayres/nino-cli/internal/logger"
@@ -119,3 +126,249 @@ func TestSanitizeModelName(t *testing.T) {
 		}
 	}
 }
+
+// TestLoadLegacyContext tests that contexts saved as JSON by previous versions are still loaded
+// and replaced by the binary format on the next save.
+func TestLoadLegacyContext(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmpDir)
+
+	modelDir := filepath.Join(tmpDir, "nino", "models", "legacy-model")
+	if err := os.MkdirAll(modelDir, 0755); err != nil {
+		t.Fatalf("Failed to create model directory: %v", err)
+	}
+	legacyFile := filepath.Join(modelDir, "context.json")
+	if err := os.WriteFile(legacyFile, []byte("[10,20,30]\n"), 0644); err != nil {
+		t.Fatalf("Failed to write legacy context file: %v", err)
+	}
+
+	loadedContext, err := LoadContext("legacy-model")
+	if err != nil {
+		t.Fatalf("LoadContext returned error: %v", err)
+	}
+	if !reflect.DeepEqual(loadedContext, []int{10, 20, 30}) {
+		t.Errorf("Expected legacy context [10 20 30], got: %v", loadedContext)
+	}
+
+	if err := SaveContext("legacy-model", []int{40}); err != nil {
+		t.Fatalf("SaveContext returned error: %v", err)
+	}
+	if _, err := os.Stat(legacyFile); !os.IsNotExist(err) {
+		t.Errorf("Expected legacy context file to be removed after saving, got: %v", err)
+	}
+}
+
+// TestSaveContextTruncates tests that only the most recent MaxContextTokens tokens are kept.
+func TestSaveContextTruncates(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	contextData := generateContext(MaxContextTokens + 10)
+	if err := SaveContext("big-model", contextData); err != nil {
+		t.Fatalf("SaveContext returned error: %v", err)
+	}
+
+	loadedContext, err := LoadContext("big-model")
+	if err != nil {
+		t.Fatalf("LoadContext returned error: %v", err)
+	}
+	if !reflect.DeepEqual(loadedContext, contextData[10:]) {
+		t.Errorf("Expected the last %d tokens to be kept, got %d tokens", MaxContextTokens, len(loadedContext))
+	}
+}
+
+// TestDecodeContext tests the binary decoder with valid and corrupt inputs.
+func TestDecodeContext(t *testing.T) {
+	valid := encodeContext([]int{0, 1, -1, 127, 128, 128255, 1 << 40})
+
+	tests := []struct {
+		name    string
+		input   []byte
+		want    []int
+		wantErr bool
+	}{
+		{"Valid context", valid, []int{0, 1, -1, 127, 128, 128255, 1 << 40}, false},
+		{"Empty context", encodeContext(nil), []int{}, false},
+		{"Empty input", []byte{}, nil, true},
+		{"JSON input", []byte("[1,2,3]"), nil, true},
+		{"Truncated input", valid[:len(valid)-2], nil, true},
+		{"Trailing bytes", append(append([]byte{}, valid...), 0x01), nil, true},
+		{"Unknown version", append([]byte("NCTX"), 0x09, 0x00), nil, true},
+		{"Huge token count", append([]byte("NCTX"), 0x01, 0xff, 0xff, 0xff, 0xff, 0x0f), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeContext(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// generateContext returns a context of the given size with token ids in the range of a
+// typical vocabulary.
+func generateContext(size int) []int {
+	rng := rand.New(rand.NewSource(42))
+	context := make([]int, size)
+	for i := range context {
+		context[i] = rng.Intn(128256)
+	}
+	return context
+}
+
+// contextSizes are realistic context sizes for the benchmarks.
+var contextSizes = []int{8 * 1024, 32 * 1024, 128 * 1024}
+
+func BenchmarkSaveContext(b *testing.B) {
+	logger.GetLogger(false)
+	b.Setenv("XDG_DATA_HOME", b.TempDir())
+
+	for _, size := range contextSizes {
+		contextData := generateContext(size)
+		b.Run(fmt.Sprintf("%dk", size/1024), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := SaveContext("bench-model", contextData); err != nil {
+					b.Fatalf("SaveContext returned error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkLoadContext(b *testing.B) {
+	logger.GetLogger(false)
+	b.Setenv("XDG_DATA_HOME", b.TempDir())
+
+	for _, size := range contextSizes {
+		if err := SaveContext("bench-model", generateContext(size)); err != nil {
+			b.Fatalf("SaveContext returned error: %v", err)
+		}
+		b.Run(fmt.Sprintf("%dk", size/1024), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := LoadContext("bench-model"); err != nil {
+					b.Fatalf("LoadContext returned error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkLoadLegacyContext measures the previous JSON format for comparison.
+func BenchmarkLoadLegacyContext(b *testing.B) {
+	logger.GetLogger(false)
+	tmpDir := b.TempDir()
+	b.Setenv("XDG_DATA_HOME", tmpDir)
+
+	modelDir := filepath.Join(tmpDir, "nino", "models", "bench-model")
+	if err := os.MkdirAll(modelDir, 0755); err != nil {
+		b.Fatalf("Failed to create model directory: %v", err)
+	}
+
+	for _, size := range contextSizes {
+		data, _ := json.Marshal(generateContext(size))
+		if err := os.WriteFile(filepath.Join(modelDir, "context.json"), data, 0644); err != nil {
+			b.Fatalf("Failed to write legacy context file: %v", err)
+		}
+		b.Run(fmt.Sprintf("%dk", size/1024), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := LoadContext("bench-model"); err != nil {
+					b.Fatalf("LoadContext returned error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// TestLoadContextCorruptFile tests that a corrupt context file is quarantined with a warning
+// instead of failing every subsequent load.
+func TestLoadContextCorruptFile(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmpDir)
+
+	var warnings bytes.Buffer
+	warningOutput = &warnings
+	defer func() { warningOutput = os.Stderr }()
+
+	if err := SaveContext("corrupt-model", []int{1, 2, 3, 4, 5}); err != nil {
+		t.Fatalf("SaveContext returned error: %v", err)
+	}
+
+	// Simulate a truncated write
+	modelDir := filepath.Join(tmpDir, "nino", "models", "corrupt-model")
+	contextFile := filepath.Join(modelDir, "context.bin")
+	data, _ := os.ReadFile(contextFile)
+	if err := os.WriteFile(contextFile, data[:len(data)-2], 0644); err != nil {
+		t.Fatalf("Failed to truncate context file: %v", err)
+	}
+
+	loadedContext, err := LoadContext("corrupt-model")
+	if err != nil {
+		t.Fatalf("LoadContext returned error for corrupt file: %v", err)
+	}
+	if loadedContext != nil {
+		t.Errorf("Expected nil context for corrupt file, got: %v", loadedContext)
+	}
+	if !strings.Contains(warnings.String(), "Warning: the context file was corrupt") {
+		t.Errorf("Expected a corruption warning, got: %q", warnings.String())
+	}
+
+	matches, _ := filepath.Glob(contextFile + ".corrupt-*")
+	if len(matches) != 1 {
+		t.Errorf("Expected the corrupt file to be quarantined, found: %v", matches)
+	}
+
+	// The next load starts from a clean context without warnings
+	warnings.Reset()
+	loadedContext, err = LoadContext("corrupt-model")
+	if err != nil || loadedContext != nil || warnings.Len() != 0 {
+		t.Errorf("Expected a clean load after quarantine, got context=%v err=%v warnings=%q", loadedContext, err, warnings.String())
+	}
+}
+
+// TestConcurrentSaveAndLoad tests that concurrent writers and readers never observe a
+// partially written context.
+func TestConcurrentSaveAndLoad(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	var warnings bytes.Buffer
+	warningOutput = &warnings
+	defer func() { warningOutput = os.Stderr }()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			if err := SaveContext("shared-model", generateContext(1000+i*100)); err != nil {
+				t.Errorf("SaveContext returned error: %v", err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := LoadContext("shared-model"); err != nil {
+				t.Errorf("LoadContext returned error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if warnings.Len() != 0 {
+		t.Errorf("Expected no corruption warnings, got: %q", warnings.String())
+	}
+}
@@ -0,0 +1,424 @@
+package files
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lucianoayres/nino-cli/internal/logger"
+)
+
+// DefaultBudget is the default total size of the attached file contents
+const DefaultBudget = 256 << 10 // 256 KiB
+
+// binarySniffLen is how much of a file is inspected to detect binary content
+const binarySniffLen = 8000
+
+// Attachment is a file included in the prompt.
+type Attachment struct {
+	Path      string // Path as displayed in the prompt
+	Size      int64  // Size of the file
+	Included  int64  // Bytes of the file included in the prompt
+	Truncated bool   // The file was cut to fit the size budget
+}
+
+// Skipped is a file left out of the prompt.
+type Skipped struct {
+	Path   string
+	Reason string
+}
+
+// Result holds the attached files formatted for the prompt and what was included.
+type Result struct {
+	Text     string
+	Included []Attachment
+	Skipped  []Skipped
+	Ignored  int   // Paths left out because of a .gitignore file
+	Budget   int64 // Size budget, 0 when unlimited
+	Used     int64 // Bytes of file content included
+}
+
+// collector gathers the attachments while expanding the patterns.
+type collector struct {
+	result  *Result
+	seen    map[string]bool
+	ignores *ignoreMatcher
+	text    strings.Builder
+	full    bool // A file was truncated, so the budget is exhausted
+}
+
+// Collect reads the files matching the given paths, which may be files, directories
+// (read recursively) or glob patterns (with ** matching any number of directories).
+// Files found through directories and globs are left out when ignored by a
+// .gitignore file, explicitly named files are always read. Binary files are
+// skipped and the contents are limited to budget bytes in total, truncating the
+// file that exceeds it and skipping the rest; a budget of 0 disables the limit.
+func Collect(patterns []string, budget int64) (*Result, error) {
+	log := logger.GetLogger(true) // Assuming logger is already initialized in main
+
+	c := &collector{
+		result:  &Result{Budget: budget},
+		seen:    map[string]bool{},
+		ignores: newIgnoreMatcher(),
+	}
+	for _, pattern := range patterns {
+		log.Info("Collecting files for '%s'", pattern)
+		if err := c.collect(pattern); err != nil {
+			return nil, err
+		}
+	}
+	c.result.Text = c.text.String()
+	log.Info("Attached %d file(s), %d bytes", len(c.result.Included), c.result.Used)
+	return c.result, nil
+}
+
+// collect adds the files of a single path or pattern.
+func (c *collector) collect(pattern string) error {
+	if !hasMeta(pattern) {
+		info, err := os.Stat(pattern)
+		if err != nil {
+			return fmt.Errorf("error reading file '%s': %v", pattern, err)
+		}
+		if info.IsDir() {
+			return c.walk(pattern)
+		}
+		return c.add(pattern)
+	}
+
+	matches, base, err := expandGlob(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid file pattern '%s': %v", pattern, err)
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no files match '%s'", pattern)
+	}
+	root := ignoreRoot(base)
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			return fmt.Errorf("error reading file '%s': %v", match, err)
+		}
+		if c.ignored(root, root, match, info.IsDir()) {
+			c.result.Ignored++
+			continue
+		}
+		if info.IsDir() {
+			err = c.walk(match)
+		} else {
+			err = c.add(match)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walk adds the files of a directory and its subdirectories.
+func (c *collector) walk(dir string) error {
+	root := ignoreRoot(dir)
+	start, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("error resolving path '%s': %v", dir, err)
+	}
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("error reading directory '%s': %v", p, err)
+		}
+		if p == dir {
+			return nil
+		}
+		if c.ignored(root, start, p, d.IsDir()) {
+			c.result.Ignored++
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		return c.add(p)
+	})
+}
+
+// ignored reports whether the path is ignored by the .gitignore files under root,
+// leaving out the directories down to start.
+func (c *collector) ignored(root, start, p string, isDir bool) bool {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return false
+	}
+	return c.ignores.Ignored(root, start, abs, isDir)
+}
+
+// add reads a file and appends it to the attachments, within the size budget.
+func (c *collector) add(p string) error {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return fmt.Errorf("error resolving path '%s': %v", p, err)
+	}
+	if c.seen[abs] {
+		return nil
+	}
+	c.seen[abs] = true
+	display := displayPath(p)
+
+	info, err := os.Stat(p)
+	if err != nil {
+		return fmt.Errorf("error reading file '%s': %v", p, err)
+	}
+
+	limit := info.Size()
+	if c.result.Budget > 0 {
+		remaining := c.result.Budget - c.result.Used
+		if remaining <= 0 || c.full {
+			c.result.Skipped = append(c.result.Skipped, Skipped{Path: display, Reason: "size budget exceeded"})
+			return nil
+		}
+		limit = min(limit, remaining)
+	}
+
+	file, err := os.Open(p)
+	if err != nil {
+		return fmt.Errorf("error reading file '%s': %v", p, err)
+	}
+	defer file.Close()
+	// Read past the limit to detect binary content in small remainders of the budget
+	content, err := io.ReadAll(io.LimitReader(file, max(limit, binarySniffLen)))
+	if err != nil {
+		return fmt.Errorf("error reading file '%s': %v", p, err)
+	}
+	if IsBinary(content) {
+		c.result.Skipped = append(c.result.Skipped, Skipped{Path: display, Reason: "binary"})
+		return nil
+	}
+
+	attachment := Attachment{Path: display, Size: info.Size()}
+	if int64(len(content)) > limit {
+		content = content[:limit]
+	}
+	if int64(len(content)) < info.Size() {
+		content = truncateAtLine(content)
+		attachment.Truncated = true
+		c.full = true
+		if len(content) == 0 {
+			c.result.Skipped = append(c.result.Skipped, Skipped{Path: display, Reason: "size budget exceeded"})
+			return nil
+		}
+	}
+	attachment.Included = int64(len(content))
+	c.result.Used += attachment.Included
+	c.result.Included = append(c.result.Included, attachment)
+
+	if c.text.Len() > 0 {
+		c.text.WriteString("\n\n")
+	}
+	c.text.WriteString(formatFile(attachment, content))
+	return nil
+}
+
+// formatFile formats an attachment with its content.
+func formatFile(a Attachment, content []byte) string {
+	note := ""
+	if a.Truncated {
+		note = fmt.Sprintf("truncated to %d of %d bytes", a.Included, a.Size)
+	}
+	return Format(a.Path, note, content)
+}
+
+// Format wraps the content of a file with a path header, followed by the note in
+// parentheses if any, and a fenced block in the language of the file.
+func Format(path, note string, content []byte) string {
+	header := "File: " + path
+	if note != "" {
+		header += " (" + note + ")"
+	}
+
+	// Use a fence longer than any run of backticks in the content
+	fence := "```"
+	for bytes.Contains(content, []byte(fence)) {
+		fence += "`"
+	}
+	body := strings.TrimRight(string(content), "\n")
+	return fmt.Sprintf("%s\n%s%s\n%s\n%s", header, fence, language(path), body, fence)
+}
+
+// Report writes a summary of the included, truncated and skipped files.
+func (r *Result) Report(w io.Writer) {
+	budget := ""
+	if r.Budget > 0 {
+		budget = fmt.Sprintf(" of the %s budget", FormatSize(r.Budget))
+	}
+	fmt.Fprintf(w, "Attached %d file(s), %s%s:\n", len(r.Included), FormatSize(r.Used), budget)
+	for _, a := range r.Included {
+		if a.Truncated {
+			fmt.Fprintf(w, "  ~ %s (truncated to %s of %s)\n", a.Path, FormatSize(a.Included), FormatSize(a.Size))
+		} else {
+			fmt.Fprintf(w, "  + %s (%s)\n", a.Path, FormatSize(a.Size))
+		}
+	}
+	for _, s := range r.Skipped {
+		fmt.Fprintf(w, "  - %s (%s)\n", s.Path, s.Reason)
+	}
+	if r.Ignored > 0 {
+		fmt.Fprintf(w, "  %d path(s) ignored by .gitignore\n", r.Ignored)
+	}
+}
+
+// FormatSize renders a size in bytes using binary units.
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value, exp := float64(size)/unit, 0
+	for value >= unit && exp < 3 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGT"[exp])
+}
+
+// IsBinary reports whether the content looks binary: it holds NUL bytes or is not UTF-8 text.
+func IsBinary(content []byte) bool {
+	sample := content[:min(len(content), binarySniffLen)]
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+	// Allow a multi-byte character cut at the end of the sample
+	for i := len(sample) - 1; i >= 0 && i >= len(sample)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(sample[i]) {
+			if !utf8.FullRune(sample[i:]) {
+				sample = sample[:i]
+			}
+			break
+		}
+	}
+	return !utf8.Valid(sample)
+}
+
+// truncateAtLine cuts the content after its last complete line, or at the last
+// complete character when it holds a single line.
+func truncateAtLine(content []byte) []byte {
+	if i := bytes.LastIndexByte(content, '\n'); i >= 0 {
+		return content[:i+1]
+	}
+	for len(content) > 0 && !utf8.Valid(content) {
+		content = content[:len(content)-1]
+	}
+	return content
+}
+
+// expandGlob returns the paths matching a glob pattern and the directory the
+// pattern is relative to. Unlike filepath.Glob, "**" matches any number of directories.
+func expandGlob(pattern string) ([]string, string, error) {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+	baseLen := 0
+	for baseLen < len(segments) && !hasMeta(segments[baseLen]) {
+		baseLen++
+	}
+	base := filepath.FromSlash(strings.Join(segments[:baseLen], "/"))
+	if base == "" {
+		base = "."
+		if strings.HasPrefix(pattern, "/") {
+			base = "/"
+		}
+	}
+
+	if !strings.Contains(pattern, "**") {
+		matches, err := filepath.Glob(pattern)
+		return matches, base, err
+	}
+
+	// Validate the pattern segments before walking
+	for _, segment := range segments[baseLen:] {
+		if _, err := filepath.Match(segment, ""); err != nil {
+			return nil, base, err
+		}
+	}
+	var matches []string
+	err := filepath.WalkDir(base, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == base && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(base, p)
+		if err != nil {
+			return err
+		}
+		if matchSegments(segments[baseLen:], strings.Split(filepath.ToSlash(rel), "/")) {
+			matches = append(matches, p)
+		}
+		return nil
+	})
+	return matches, base, err
+}
+
+// ignoreRoot returns the directory whose .gitignore files apply to the given
+// path: the root of its git repository, or the path itself outside a repository.
+func ignoreRoot(p string) string {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+	if root := gitRoot(abs); root != "" {
+		return root
+	}
+	return abs
+}
+
+// hasMeta reports whether the path holds any of the glob special characters.
+func hasMeta(p string) bool {
+	return strings.ContainsAny(p, `*?[`)
+}
+
+// displayPath returns the path relative to the working directory when it is inside it.
+func displayPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, abs); err == nil && !strings.HasPrefix(rel, "..") {
+				p = rel
+			}
+		}
+	}
+	return filepath.ToSlash(p)
+}
+
+// languages maps file extensions to the language of their fenced blocks, when they differ.
+var languages = map[string]string{
+	".py":  "python",
+	".js":  "javascript",
+	".ts":  "typescript",
+	".rs":  "rust",
+	".rb":  "ruby",
+	".sh":  "bash",
+	".yml": "yaml",
+	".md":  "markdown",
+	".h":   "c",
+	".hpp": "cpp",
+	".cc":  "cpp",
+}
+
+// language returns the language of the fenced block of a file, from its extension.
+func language(p string) string {
+	ext := strings.ToLower(filepath.Ext(p))
+	if lang, ok := languages[ext]; ok {
+		return lang
+	}
+	return strings.TrimPrefix(ext, ".")
+}
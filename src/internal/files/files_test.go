@@ -0,0 +1,198 @@
+package files
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lucianoayres/nino-cli/internal/logger"
+)
+
+// chdir changes the working directory for the duration of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get the working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change the working directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestCollect(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".git/HEAD":         "ref: refs/heads/main\n",
+		".gitignore":        "*.log\n",
+		"main.go":           "package main\n",
+		"README.md":         "# Title\n",
+		"internal/a/a.go":   "package a\n",
+		"internal/a/a.txt":  "text\n",
+		"internal/b/b.go":   "package b\n",
+		"debug.log":         "log line\n",
+		"logo.png":          "\x89PNG\r\n\x1a\n\x00\x00",
+		"fence.md":          "```go\ncode\n```\n",
+		"large.txt":         "line 1\nline 2\nline 3\n",
+		"internal/b/b.log":  "ignored\n",
+		"internal/a/.keep":  "",
+		"internal/c/c.json": "{}\n",
+	})
+	chdir(t, dir)
+
+	tests := []struct {
+		name         string
+		patterns     []string
+		budget       int64
+		wantIncluded []string
+		wantSkipped  []Skipped
+		wantIgnored  int
+		wantText     string
+		wantErr      bool
+	}{
+		{
+			name:         "Single file",
+			patterns:     []string{"main.go"},
+			wantIncluded: []string{"main.go"},
+			wantText:     "File: main.go\n```go\npackage main\n```",
+		},
+		{
+			name:         "Explicit ignored file",
+			patterns:     []string{"debug.log"},
+			wantIncluded: []string{"debug.log"},
+			wantText:     "File: debug.log\n```log\nlog line\n```",
+		},
+		{
+			name:         "Directory",
+			patterns:     []string{"internal"},
+			wantIncluded: []string{"internal/a/.keep", "internal/a/a.go", "internal/a/a.txt", "internal/b/b.go", "internal/c/c.json"},
+			wantIgnored:  1,
+		},
+		{
+			name:         "Glob",
+			patterns:     []string{"*.md"},
+			wantIncluded: []string{"README.md", "fence.md"},
+			wantText:     "File: README.md\n```markdown\n# Title\n```\n\nFile: fence.md\n````markdown\n```go\ncode\n```\n````",
+		},
+		{
+			name:         "Recursive glob and duplicates",
+			patterns:     []string{"**/*.go", "main.go"},
+			wantIncluded: []string{"internal/a/a.go", "internal/b/b.go", "main.go"},
+		},
+		{
+			name:         "Glob honours gitignore",
+			patterns:     []string{"*.log"},
+			wantIncluded: nil,
+			wantIgnored:  1,
+		},
+		{
+			name:         "Binary file",
+			patterns:     []string{"logo.png", "main.go"},
+			wantIncluded: []string{"main.go"},
+			wantSkipped:  []Skipped{{Path: "logo.png", Reason: "binary"}},
+		},
+		{
+			name:         "Size budget",
+			patterns:     []string{"main.go", "large.txt", "README.md"},
+			budget:       int64(len("package main\n") + len("line 1\nline 2\nli")),
+			wantIncluded: []string{"main.go", "large.txt"},
+			wantSkipped:  []Skipped{{Path: "README.md", Reason: "size budget exceeded"}},
+			wantText:     "File: main.go\n```go\npackage main\n```\n\nFile: large.txt (truncated to 14 of 21 bytes)\n```txt\nline 1\nline 2\n```",
+		},
+		{
+			name:     "Missing file",
+			patterns: []string{"missing.go"},
+			wantErr:  true,
+		},
+		{
+			name:     "No matches",
+			patterns: []string{"*.rs"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Collect(tt.patterns, tt.budget)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Collect() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Collect() unexpected error: %v", err)
+			}
+
+			var included []string
+			for _, a := range result.Included {
+				included = append(included, a.Path)
+			}
+			if !reflect.DeepEqual(included, tt.wantIncluded) {
+				t.Errorf("Collect() included = %v, want %v", included, tt.wantIncluded)
+			}
+			if !reflect.DeepEqual(result.Skipped, tt.wantSkipped) {
+				t.Errorf("Collect() skipped = %v, want %v", result.Skipped, tt.wantSkipped)
+			}
+			if result.Ignored != tt.wantIgnored {
+				t.Errorf("Collect() ignored = %d, want %d", result.Ignored, tt.wantIgnored)
+			}
+			if tt.wantText != "" && result.Text != tt.wantText {
+				t.Errorf("Collect() text = %q, want %q", result.Text, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestResult_Report(t *testing.T) {
+	result := &Result{
+		Included: []Attachment{
+			{Path: "main.go", Size: 2048, Included: 2048},
+			{Path: "big.log", Size: 4 << 20, Included: 1024, Truncated: true},
+		},
+		Skipped: []Skipped{{Path: "logo.png", Reason: "binary"}},
+		Ignored: 3,
+		Budget:  3072,
+		Used:    3072,
+	}
+
+	var buf bytes.Buffer
+	result.Report(&buf)
+	want := strings.Join([]string{
+		"Attached 2 file(s), 3.0 KiB of the 3.0 KiB budget:",
+		"  + main.go (2.0 KiB)",
+		"  ~ big.log (truncated to 1.0 KiB of 4.0 MiB)",
+		"  - logo.png (binary)",
+		"  3 path(s) ignored by .gitignore",
+		"",
+	}, "\n")
+	if buf.String() != want {
+		t.Errorf("Report() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    bool
+	}{
+		{"Text", []byte("hello\n"), false},
+		{"UTF-8 text", []byte("olá, mundo\n"), false},
+		{"NUL byte", []byte("a\x00b"), true},
+		{"Invalid UTF-8", []byte{0xff, 0xfe, 0x41}, true},
+		{"Character cut at the end of the sample", append(bytes.Repeat([]byte("a"), binarySniffLen-1), "é"...), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsBinary(tt.content); got != tt.want {
+			t.Errorf("IsBinary() for %s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
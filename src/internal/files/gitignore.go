@@ -0,0 +1,162 @@
+package files
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is a pattern of a .gitignore file.
+type ignoreRule struct {
+	segments []string // Pattern segments relative to the directory of the .gitignore file
+	negate   bool     // The pattern re-includes paths ignored by a previous pattern
+	dirOnly  bool     // The pattern only matches directories
+}
+
+// parseIgnoreRule parses a line of a .gitignore file, returning false for blank lines and comments.
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	// Patterns without a slash match at any depth, the others are relative to the .gitignore file
+	if !strings.Contains(line, "/") {
+		line = "**/" + line
+	}
+	rule.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+	return rule, true
+}
+
+// ignoreMatcher decides whether paths are ignored by the .gitignore files found
+// from a root directory down to the path.
+type ignoreMatcher struct {
+	rules map[string][]ignoreRule // Rules of the .gitignore file of each directory
+}
+
+// newIgnoreMatcher returns an empty ignoreMatcher.
+func newIgnoreMatcher() *ignoreMatcher {
+	return &ignoreMatcher{rules: map[string][]ignoreRule{}}
+}
+
+// dirRules returns the rules of the .gitignore file in dir, reading it on first use.
+func (m *ignoreMatcher) dirRules(dir string) []ignoreRule {
+	if rules, ok := m.rules[dir]; ok {
+		return rules
+	}
+	var rules []ignoreRule
+	if file, err := os.Open(filepath.Join(dir, ".gitignore")); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if rule, ok := parseIgnoreRule(scanner.Text()); ok {
+				rules = append(rules, rule)
+			}
+		}
+		file.Close()
+	}
+	m.rules[dir] = rules
+	return rules
+}
+
+// Ignored reports whether the path, which must be inside root, is ignored. A path
+// inside an ignored directory is ignored too, as with git, except for the
+// directories from root down to start, which were explicitly requested.
+func (m *ignoreMatcher) Ignored(root, start, p string, isDir bool) bool {
+	rel, err := filepath.Rel(root, p)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	first := 0
+	if startRel, err := filepath.Rel(root, start); err == nil && startRel != "." {
+		first = len(strings.Split(filepath.ToSlash(startRel), "/"))
+	}
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	for i := first; i < len(segments); i++ {
+		if segments[i] == ".git" {
+			return true
+		}
+		last := i == len(segments)-1
+		if m.matches(root, segments[:i+1], !last || isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches applies the rules of the .gitignore files from root down to the parent
+// of the path given by its segments; the last matching rule wins.
+func (m *ignoreMatcher) matches(root string, segments []string, isDir bool) bool {
+	ignored := false
+	dir := root
+	for depth := 0; depth < len(segments); depth++ {
+		for _, rule := range m.dirRules(dir) {
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			if matchSegments(rule.segments, segments[depth:]) {
+				ignored = !rule.negate
+			}
+		}
+		dir = filepath.Join(dir, segments[depth])
+	}
+	return ignored
+}
+
+// matchSegments matches path segments against pattern segments, where "**"
+// matches zero or more segments (one or more at the end, i.e. everything inside)
+// and the others follow path.Match.
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" && len(pattern) == 1 {
+		return len(segments) > 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+// gitRoot returns the nearest directory from dir upwards containing a .git entry,
+// or an empty string if dir is not inside a git repository.
+func gitRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,94 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/nino/main.go", true},
+		{"cmd/**/*.go", "cmd/nino/main.go", true},
+		{"cmd/**", "cmd/nino/main.go", true},
+		{"cmd/**", "cmd", false},
+		{"internal/*/config.go", "internal/config/config.go", true},
+		{"[ab].txt", "c.txt", false},
+	}
+
+	for _, tt := range tests {
+		got := matchSegments(strings.Split(tt.pattern, "/"), strings.Split(tt.path, "/"))
+		if got != tt.want {
+			t.Errorf("matchSegments(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":          "# Build output\n*.log\n/build/\nnode_modules/\n!keep.log\ndocs/*.tmp\n",
+		"src/.gitignore":      "generated.go\n",
+		"src/main.go":         "",
+		"src/generated.go":    "",
+		"app.log":             "",
+		"keep.log":            "",
+		"build/out.txt":       "",
+		"src/build/out.txt":   "",
+		"docs/a.tmp":          "",
+		"docs/sub/b.tmp":      "",
+		"node_modules/x/y.js": "",
+	})
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"src/main.go", false, false},
+		{"src/generated.go", false, true},
+		{"app.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build/out.txt", false, true},
+		{"src/build/out.txt", false, false}, // Anchored to the root
+		{"docs/a.tmp", false, true},
+		{"docs/sub/b.tmp", false, false},
+		{"node_modules/x/y.js", false, true},
+		{".git/config", false, true},
+	}
+
+	m := newIgnoreMatcher()
+	for _, tt := range tests {
+		if got := m.Ignored(root, root, filepath.Join(root, tt.path), tt.isDir); got != tt.want {
+			t.Errorf("Ignored(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	// Directories down to start are not checked, as they were requested explicitly
+	if m.Ignored(root, filepath.Join(root, "build"), filepath.Join(root, "build", "out.txt"), false) {
+		t.Error("Ignored() for a file in an explicitly requested directory = true, want false")
+	}
+}
+
+// writeFiles creates the files, given by their paths relative to dir, with their contents.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data to the named file so that readers only ever observe
+// the previous or the new content. The data is written to a temporary file in the
+// same directory, which is then renamed over the destination.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	tmpPath := tmp.Name()
+
+	// Remove the temporary file if anything goes wrong before the rename
+	success := false
+	defer func() {
+		if !success {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("failed to write temporary file: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync temporary file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %v", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("failed to set file permissions: %v", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace file: %v", err)
+	}
+
+	success = true
+	return nil
+}
+
+// Lock is an advisory lock held on a lock file.
+type Lock struct {
+	file *os.File
+}
+
+// LockFile acquires an advisory lock on the named lock file, creating it if needed.
+// Exclusive locks are used by writers and shared locks by readers. It blocks until
+// the lock is available.
+func LockFile(path string, exclusive bool) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %v", err)
+	}
+	if err := lockFile(file, exclusive); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock file '%s': %v", path, err)
+	}
+	return &Lock{file: file}, nil
+}
+
+// Unlock releases the lock.
+func (l *Lock) Unlock() error {
+	if err := unlockFile(l.file); err != nil {
+		l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
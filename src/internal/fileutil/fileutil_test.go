@@ -0,0 +1,98 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "data.bin")
+
+	if err := WriteFileAtomic(path, []byte("first"), 0644); err != nil {
+		t.Fatalf("WriteFileAtomic returned error: %v", err)
+	}
+	if err := WriteFileAtomic(path, []byte("second"), 0600); err != nil {
+		t.Fatalf("WriteFileAtomic returned error on overwrite: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(data) != "second" {
+		t.Errorf("Expected file content 'second', got: %q", data)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Failed to stat file: %v", err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("Expected permissions 0600, got: %v", info.Mode().Perm())
+		}
+	}
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the destination file, got %d entries", len(entries))
+	}
+}
+
+func TestWriteFileAtomicMissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "data.bin")
+	if err := WriteFileAtomic(path, []byte("data"), 0644); err == nil {
+		t.Error("Expected error when the directory does not exist, got nil")
+	}
+}
+
+func TestLockFileExclusive(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("File locking is not supported on this platform")
+	}
+
+	path := filepath.Join(t.TempDir(), "test.lock")
+
+	first, err := LockFile(path, true)
+	if err != nil {
+		t.Fatalf("LockFile returned error: %v", err)
+	}
+
+	acquired := make(chan *Lock)
+	go func() {
+		second, err := LockFile(path, false)
+		if err != nil {
+			t.Errorf("LockFile returned error in goroutine: %v", err)
+			close(acquired)
+			return
+		}
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Shared lock acquired while an exclusive lock was held")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := first.Unlock(); err != nil {
+		t.Fatalf("Unlock returned error: %v", err)
+	}
+
+	select {
+	case second := <-acquired:
+		if second != nil {
+			second.Unlock()
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Shared lock not acquired after the exclusive lock was released")
+	}
+}
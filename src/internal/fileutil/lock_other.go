@@ -0,0 +1,16 @@
+//go:build !unix
+
+package fileutil
+
+import "os"
+
+// lockFile is a no-op on platforms without flock(2). Writes remain atomic
+// thanks to WriteFileAtomic, but concurrent writers are not serialized.
+func lockFile(file *os.File, exclusive bool) error {
+	return nil
+}
+
+// unlockFile is a no-op on platforms without flock(2).
+func unlockFile(file *os.File) error {
+	return nil
+}
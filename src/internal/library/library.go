@@ -0,0 +1,195 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lucianoayres/nino-cli/internal/logger"
+	"github.com/lucianoayres/nino-cli/internal/project"
+	"github.com/lucianoayres/nino-cli/internal/promptfile"
+	"github.com/lucianoayres/nino-cli/internal/utils"
+)
+
+// Scopes of the prompt library directories
+const (
+	ScopeProject = "project" // .nino/prompts in the working directory, shared through the repository
+	ScopeUser    = "user"    // $XDG_CONFIG_HOME/nino/prompts, private to the user
+)
+
+// NewFileExtension is the extension of the prompt files created in the library
+const NewFileExtension = ".prompt"
+
+// validName matches the prompt names, which may be grouped in directories (e.g. git/commit).
+var validName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*(/[a-zA-Z0-9][a-zA-Z0-9._-]*)*$`)
+
+// Dir is a directory of the prompt library.
+type Dir struct {
+	Path  string
+	Scope string
+}
+
+// Prompt is a named prompt of the library.
+type Prompt struct {
+	Name        string
+	Path        string
+	Scope       string
+	Description string // The description of the front matter, or the first line of the prompt
+}
+
+// ValidateName checks that the prompt name can be safely used as a file path.
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid prompt name '%s': use letters, digits, '.', '-' and '_', and '/' to group prompts", name)
+	}
+	return nil
+}
+
+// Dirs returns the directories of the library, the project one first as its
+// prompts take precedence over the user ones with the same name. The project
+// directory is in the root of the project of the working directory, or in the
+// working directory when there is no project.
+func Dirs() ([]Dir, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine the working directory: %v", err)
+	}
+	if p := project.Find(root); p != nil {
+		root = p.Root
+	}
+	configDir, err := utils.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return []Dir{
+		{Path: filepath.Join(root, project.DirName, "prompts"), Scope: ScopeProject},
+		{Path: filepath.Join(configDir, "nino", "prompts"), Scope: ScopeUser},
+	}, nil
+}
+
+// List returns the prompts of the library sorted by name. Prompts hidden by a
+// project prompt with the same name are left out.
+func List() ([]Prompt, error) {
+	log := logger.GetLogger(true) // Assuming logger is already initialized in main
+
+	dirs, err := Dirs()
+	if err != nil {
+		return nil, err
+	}
+
+	var prompts []Prompt
+	seen := map[string]bool{}
+	for _, dir := range dirs {
+		log.Info("Listing prompts in %s", dir.Path)
+		err := filepath.WalkDir(dir.Path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == dir.Path && errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if strings.HasPrefix(d.Name(), ".") && path != dir.Path {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(dir.Path, path)
+			if err != nil {
+				return err
+			}
+			name := strings.TrimSuffix(filepath.ToSlash(rel), filepath.Ext(rel))
+			if seen[name] || ValidateName(name) != nil {
+				return nil
+			}
+			seen[name] = true
+			prompts = append(prompts, Prompt{Name: name, Path: path, Scope: dir.Scope, Description: describe(path)})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing prompts in '%s': %v", dir.Path, err)
+		}
+	}
+
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
+	return prompts, nil
+}
+
+// Find returns the prompt with the given name, looking in the project library first.
+func Find(name string) (*Prompt, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	dirs, err := Dirs()
+	if err != nil {
+		return nil, err
+	}
+
+	var searched []string
+	for _, dir := range dirs {
+		base := filepath.Join(dir.Path, filepath.FromSlash(name))
+		candidates, err := filepath.Glob(base + ".*")
+		if err != nil {
+			return nil, err
+		}
+		candidates = append([]string{base}, candidates...)
+		for _, candidate := range candidates {
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return &Prompt{Name: name, Path: candidate, Scope: dir.Scope, Description: describe(candidate)}, nil
+			}
+		}
+		searched = append(searched, dir.Path)
+	}
+	return nil, fmt.Errorf("prompt '%s' not found in %s", name, strings.Join(searched, " or "))
+}
+
+// NewPath returns the path of a new prompt with the given name in the library of the scope.
+func NewPath(name, scope string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	dirs, err := Dirs()
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range dirs {
+		if dir.Scope == scope {
+			return filepath.Join(dir.Path, filepath.FromSlash(name)+NewFileExtension), nil
+		}
+	}
+	return "", fmt.Errorf("unknown prompt library scope '%s'", scope)
+}
+
+// describe returns the description of a prompt file: the description of its front
+// matter, or else the first line of the prompt.
+func describe(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	frontMatter, prompt, err := promptfile.ParseFrontMatter(string(content))
+	if err != nil {
+		return "(invalid front matter)"
+	}
+	if frontMatter != nil && frontMatter.Description != "" {
+		return frontMatter.Description
+	}
+	for _, line := range strings.Split(prompt, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			if len(line) > 60 {
+				line = line[:57] + "..."
+			}
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lucianoayres/nino-cli/internal/logger"
+)
+
+// setupLibrary creates a project and a user library with the given files and
+// changes to the project directory for the duration of the test.
+func setupLibrary(t *testing.T, files map[string]string) (project, user string) {
+	t.Helper()
+	logger.GetLogger(true)
+
+	project, configDir := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	for name, content := range files {
+		path := filepath.Join(project, filepath.FromSlash(name))
+		if strings.HasPrefix(name, "user/") {
+			path = filepath.Join(configDir, "nino", "prompts", filepath.FromSlash(strings.TrimPrefix(name, "user/")))
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(project); err != nil {
+		t.Fatalf("Failed to change the working directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	// Resolve symbolic links in the temporary directories, as Getwd does
+	project, _ = os.Getwd()
+	return filepath.Join(project, ".nino", "prompts"), filepath.Join(configDir, "nino", "prompts")
+}
+
+func TestList(t *testing.T) {
+	project, user := setupLibrary(t, map[string]string{
+		".nino/prompts/commit.prompt": "---\ndescription: Conventional commit message\n---\nWrite a commit message.",
+		".nino/prompts/review.md":     "\nReview the changes for bugs.\n",
+		"user/commit.txt":             "Shadowed by the project prompt",
+		"user/git/status.tmpl":        "Analyze {{shell \"git status\"}}",
+		"user/.hidden":                "Not a prompt",
+	})
+
+	got, err := List()
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	want := []Prompt{
+		{Name: "commit", Path: filepath.Join(project, "commit.prompt"), Scope: ScopeProject, Description: "Conventional commit message"},
+		{Name: "git/status", Path: filepath.Join(user, "git", "status.tmpl"), Scope: ScopeUser, Description: "Analyze {{shell \"git status\"}}"},
+		{Name: "review", Path: filepath.Join(project, "review.md"), Scope: ScopeProject, Description: "Review the changes for bugs."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestList_Empty(t *testing.T) {
+	setupLibrary(t, nil)
+
+	got, err := List()
+	if err != nil || len(got) != 0 {
+		t.Errorf("List() = %v, %v; want no prompts", got, err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	project, user := setupLibrary(t, map[string]string{
+		".nino/prompts/commit.prompt": "Project commit prompt",
+		"user/commit.txt":             "User commit prompt",
+		"user/git/status.tmpl":        "Status prompt",
+	})
+
+	tests := []struct {
+		name     string
+		wantPath string
+		wantErr  bool
+	}{
+		{"commit", filepath.Join(project, "commit.prompt"), false},
+		{"git/status", filepath.Join(user, "git", "status.tmpl"), false},
+		{"missing", "", true},
+		{"../commit", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Find(tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Find(%q) expected error but got %+v", tt.name, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Find(%q) unexpected error: %v", tt.name, err)
+			}
+			if got.Path != tt.wantPath {
+				t.Errorf("Find(%q) path = %s, want %s", tt.name, got.Path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestNewPath(t *testing.T) {
+	project, user := setupLibrary(t, nil)
+
+	if got, err := NewPath("git/commit", ScopeUser); err != nil || got != filepath.Join(user, "git", "commit.prompt") {
+		t.Errorf("NewPath(user) = %s, %v", got, err)
+	}
+	if got, err := NewPath("commit", ScopeProject); err != nil || got != filepath.Join(project, "commit.prompt") {
+		t.Errorf("NewPath(project) = %s, %v", got, err)
+	}
+	if _, err := NewPath("bad name", ScopeUser); err == nil {
+		t.Error("NewPath() with an invalid name expected an error but got none")
+	}
+}
+
+func TestDirs_FromSubdirectory(t *testing.T) {
+	project, _ := setupLibrary(t, map[string]string{
+		".nino/prompts/commit.prompt": "Project commit prompt",
+		"internal/cmd/main.go":        "package main",
+	})
+	wd, _ := os.Getwd()
+	if err := os.Chdir(filepath.Join(wd, "internal", "cmd")); err != nil {
+		t.Fatalf("Failed to change the working directory: %v", err)
+	}
+
+	got, err := Find("commit")
+	if err != nil {
+		t.Fatalf("Find() unexpected error: %v", err)
+	}
+	if want := filepath.Join(project, "commit.prompt"); got.Path != want {
+		t.Errorf("Find() path = %s, want %s", got.Path, want)
+	}
+}
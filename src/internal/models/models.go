@@ -1,5 +1,7 @@
 package models
 
+import "encoding/json"
+
 // ResponsePayload represents the structure of each JSON object in the response stream.
 type ResponsePayload struct {
 	Model     string `json:"model"`
@@ -11,11 +13,106 @@ type ResponsePayload struct {
 
 // RequestPayload represents the payload sent in the HTTP request.
 type RequestPayload struct {
-	Model      string   `json:"model"`
-	Prompt     string   `json:"prompt"`
-	Images     []string `json:"images"` // New field for images in base64
-	Format     string   `json:"format"`
-	Stream     bool     `json:"stream"`
-	Keep_Alive string   `json:"keep_alive,omitempty"`
-	Context    []int    `json:"context,omitempty"`
+	Model      string                 `json:"model"`
+	Prompt     string                 `json:"prompt"`
+	System     string                 `json:"system,omitempty"`
+	Images     []string               `json:"images"`           // New field for images in base64
+	Format     json.RawMessage        `json:"format,omitempty"` // "json" or a JSON schema
+	Stream     bool                   `json:"stream"`
+	Keep_Alive string                 `json:"keep_alive,omitempty"`
+	Context    []int                  `json:"context,omitempty"`
+	Options    map[string]interface{} `json:"options,omitempty"`
+}
+
+// Message represents a single message of a chat conversation.
+type Message struct {
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Images  []string `json:"images,omitempty"` // Images in base64
+}
+
+// ChatRequestPayload represents the payload sent to the chat endpoint.
+type ChatRequestPayload struct {
+	Model      string                 `json:"model"`
+	Messages   []Message              `json:"messages"`
+	Format     json.RawMessage        `json:"format,omitempty"` // "json" or a JSON schema
+	Stream     bool                   `json:"stream"`
+	Keep_Alive string                 `json:"keep_alive,omitempty"`
+	Options    map[string]interface{} `json:"options,omitempty"`
+}
+
+// ChatResponsePayload represents the structure of each JSON object in the chat response stream.
+type ChatResponsePayload struct {
+	Model     string  `json:"model"`
+	CreatedAt string  `json:"created_at"`
+	Message   Message `json:"message"`
+	Done      bool    `json:"done"`
+}
+
+// ModelDetails holds the details of a model.
+type ModelDetails struct {
+	Format            string `json:"format"`
+	Family            string `json:"family"`
+	ParameterSize     string `json:"parameter_size"`
+	QuantizationLevel string `json:"quantization_level"`
+}
+
+// ModelInfo describes a local model as returned by the tags endpoint.
+type ModelInfo struct {
+	Name       string       `json:"name"`
+	Model      string       `json:"model"`
+	ModifiedAt string       `json:"modified_at"`
+	Size       int64        `json:"size"`
+	Digest     string       `json:"digest"`
+	Details    ModelDetails `json:"details"`
+}
+
+// ListModelsResponse represents the response of the tags endpoint.
+type ListModelsResponse struct {
+	Models []ModelInfo `json:"models"`
+}
+
+// RunningModel describes a model loaded in memory as returned by the ps endpoint.
+type RunningModel struct {
+	Name      string       `json:"name"`
+	Model     string       `json:"model"`
+	Size      int64        `json:"size"`
+	SizeVRAM  int64        `json:"size_vram"`
+	ExpiresAt string       `json:"expires_at"`
+	Details   ModelDetails `json:"details"`
+}
+
+// ListRunningModelsResponse represents the response of the ps endpoint.
+type ListRunningModelsResponse struct {
+	Models []RunningModel `json:"models"`
+}
+
+// ModelRequest is the payload of the show, pull and delete endpoints.
+type ModelRequest struct {
+	Model  string `json:"model"`
+	Stream *bool  `json:"stream,omitempty"`
+}
+
+// ShowModelResponse represents the response of the show endpoint.
+type ShowModelResponse struct {
+	License    string                 `json:"license"`
+	Modelfile  string                 `json:"modelfile"`
+	Parameters string                 `json:"parameters"`
+	Template   string                 `json:"template"`
+	Details    ModelDetails           `json:"details"`
+	ModelInfo  map[string]interface{} `json:"model_info"`
+}
+
+// PullProgress represents each JSON object in the pull progress stream.
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest"`
+	Total     int64  `json:"total"`
+	Completed int64  `json:"completed"`
+	Error     string `json:"error"`
+}
+
+// VersionResponse represents the response of the version endpoint.
+type VersionResponse struct {
+	Version string `json:"version"`
 }
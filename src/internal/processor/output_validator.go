@@ -0,0 +1,40 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lucianoayres/nino-cli/internal/logger"
+	"github.com/lucianoayres/nino-cli/internal/schema"
+)
+
+// ValidateOutput checks that the aggregated response is a JSON document conforming
+// to the given schema. When the schema is nil, it only checks that the response is
+// valid JSON. Validation failures wrap a *schema.ValidationError.
+func ValidateOutput(output []byte, s *schema.Schema) error {
+	log := logger.GetLogger(true) // Assuming logger is already initialized in main
+	log.Info("Validating %d byte(s) of output", len(output))
+
+	if s == nil {
+		var value interface{}
+		if err := json.Unmarshal(output, &value); err != nil {
+			log.Error("Output validation error: %v", err)
+			return fmt.Errorf("the model output is not valid JSON: %w", &schema.ValidationError{Path: "$", Message: err.Error()})
+		}
+		log.Info("Output is valid JSON")
+		return nil
+	}
+
+	if len(bytes.TrimSpace(output)) == 0 {
+		log.Error("Output is empty")
+		return fmt.Errorf("the model output does not match the JSON schema: %w", &schema.ValidationError{Path: "$", Message: "the output is empty"})
+	}
+	if err := s.ValidateJSON(output); err != nil {
+		log.Error("Output validation error: %v", err)
+		return fmt.Errorf("the model output does not match the JSON schema: %w", err)
+	}
+
+	log.Info("Output conforms to the JSON schema")
+	return nil
+}
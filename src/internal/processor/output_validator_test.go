@@ -0,0 +1,78 @@
+package processor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lucianoayres/nino-cli/internal/logger"
+	"github.com/lucianoayres/nino-cli/internal/schema"
+)
+
+// TestValidateOutput tests the ValidateOutput function with conforming and non-conforming outputs.
+func TestValidateOutput(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	s, err := schema.Compile([]byte(`{"type": "object", "properties": {"answer": {"type": "integer"}}, "required": ["answer"]}`))
+	if err != nil {
+		t.Fatalf("Failed to compile schema: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		output  string
+		wantErr bool
+	}{
+		{"Conforming output", `{"answer": 42}`, false},
+		{"Conforming output with surrounding whitespace", "\n {\"answer\": 42}\n", false},
+		{"Empty output", "  ", true},
+		{"Malformed JSON", `{"answer": 42`, true},
+		{"Non-conforming output", `{"answer": "forty-two"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOutput([]byte(tt.output), s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				var validationErr *schema.ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Errorf("Expected the error to wrap a *schema.ValidationError, got: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// TestValidateOutputWithoutSchema tests that only the JSON syntax is checked when no schema is given.
+func TestValidateOutputWithoutSchema(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	tests := []struct {
+		name    string
+		output  string
+		wantErr bool
+	}{
+		{"Object", `{"answer": 42}`, false},
+		{"Array", `[1, 2, 3]`, false},
+		{"Empty output", ``, true},
+		{"Text around JSON", `Sure! {"answer": 42}`, true},
+		{"Truncated JSON", `{"answer": `, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOutput([]byte(tt.output), nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var validationErr *schema.ValidationError
+			if err != nil && !errors.As(err, &validationErr) {
+				t.Errorf("Expected the error to wrap a *schema.ValidationError, got: %v", err)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/lucianoayres/nino-cli/internal/logger"
 	"github.com/lucianoayres/nino-cli/internal/models"
@@ -31,13 +32,16 @@ func ProcessResponse(body io.Reader, writer io.Writer, contextHandler func([]int
 
 		if r.Response != "" {
 			log.Info("Writing response to writer: %s", r.Response)
-			fmt.Fprint(writer, r.Response)
+			if _, err := fmt.Fprint(writer, r.Response); err != nil {
+				log.Error("Write error: %v", err)
+				return fmt.Errorf("failed to write response: %w", err)
+			}
 		}
 
 		if r.Done {
 			log.Info("Response marked as done")
 			if len(r.Context) > 0 && contextHandler != nil {
-				log.Info("Handling context data: %v", r.Context)
+				log.Info("Handling context data: %d tokens", len(r.Context))
 				if err := contextHandler(r.Context); err != nil {
 					log.Error("Context handler error: %v", err)
 					return fmt.Errorf("failed to handle context: %v", err)
@@ -49,3 +53,48 @@ func ProcessResponse(body io.Reader, writer io.Writer, contextHandler func([]int
 	log.Info("Finished processing response")
 	return nil
 }
+
+// ProcessChatResponse reads and processes the response from the chat endpoint.
+// It writes each message content delta to the provided writer as it arrives and
+// returns the complete assistant message once the stream is done.
+func ProcessChatResponse(body io.Reader, writer io.Writer) (models.Message, error) {
+	log := logger.GetLogger(true) // Assuming logger is already initialized in main
+	log.Info("Starting to process chat response")
+	decoder := json.NewDecoder(body)
+
+	message := models.Message{Role: "assistant"}
+	var content strings.Builder
+	for {
+		var r models.ChatResponsePayload
+		if err := decoder.Decode(&r); err == io.EOF {
+			log.Info("End of chat response stream")
+			break
+		} else if err != nil {
+			log.Error("JSON decoding error: %v", err)
+			return message, fmt.Errorf("failed to decode JSON response: %v", err)
+		}
+
+		log.Info("Received ChatResponsePayload: Model=%s, CreatedAt=%s, Done=%v", r.Model, r.CreatedAt, r.Done)
+
+		if r.Message.Role != "" {
+			message.Role = r.Message.Role
+		}
+		if r.Message.Content != "" {
+			log.Info("Writing message content to writer: %s", r.Message.Content)
+			content.WriteString(r.Message.Content)
+			if _, err := fmt.Fprint(writer, r.Message.Content); err != nil {
+				log.Error("Write error: %v", err)
+				message.Content = content.String()
+				return message, fmt.Errorf("failed to write response: %w", err)
+			}
+		}
+
+		if r.Done {
+			log.Info("Chat response marked as done")
+			break
+		}
+	}
+	message.Content = content.String()
+	log.Info("Finished processing chat response")
+	return message, nil
+}
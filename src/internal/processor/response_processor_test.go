@@ -3,6 +3,8 @@ package processor
 import (
 	"bytes"
 	"errors"
+	"strings"
+	"syscall"
 	"testing"
 
 	"github.com/lucianoayres/nino-cli/internal/logger"
@@ -177,3 +179,113 @@ func TestProcessResponse(t *testing.T) {
 		})
 	}
 }
+
+// TestProcessChatResponse tests the ProcessChatResponse function with various input scenarios.
+func TestProcessChatResponse(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	tests := []struct {
+		name        string
+		input       string
+		wantOutput  string
+		wantMessage string
+		wantErr     bool
+	}{
+		{
+			name: "Streamed message deltas",
+			input: `{"message": {"role": "assistant", "content": "Hello"}, "done": false}
+{"message": {"role": "assistant", "content": " World"}, "done": false}
+{"message": {"role": "assistant", "content": ""}, "done": true}`,
+			wantOutput:  "Hello World",
+			wantMessage: "Hello World",
+		},
+		{
+			name:        "Single non-streamed message",
+			input:       `{"message": {"role": "assistant", "content": "こんにちは"}, "done": true}`,
+			wantOutput:  "こんにちは",
+			wantMessage: "こんにちは",
+		},
+		{
+			name: "Stops reading after done",
+			input: `{"message": {"role": "assistant", "content": "First"}, "done": true}
+{"message": {"role": "assistant", "content": "Second"}, "done": false}`,
+			wantOutput:  "First",
+			wantMessage: "First",
+		},
+		{
+			name:        "Empty input",
+			input:       ``,
+			wantOutput:  "",
+			wantMessage: "",
+		},
+		{
+			name: "Malformed JSON input",
+			input: `{"message": {"role": "assistant", "content": "Hello"}, "done": false}
+{"message": {"role": "assistant"`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			var writer bytes.Buffer
+
+			message, err := ProcessChatResponse(bytes.NewReader([]byte(tt.input)), &writer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessChatResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := writer.String(); got != tt.wantOutput {
+				t.Errorf("ProcessChatResponse() output = %q, want %q", got, tt.wantOutput)
+			}
+			if message.Role != "assistant" {
+				t.Errorf("ProcessChatResponse() role = %q, want %q", message.Role, "assistant")
+			}
+			if message.Content != tt.wantMessage {
+				t.Errorf("ProcessChatResponse() content = %q, want %q", message.Content, tt.wantMessage)
+			}
+		})
+	}
+}
+
+// brokenPipeWriter fails like a write to a pipe whose reader is gone.
+type brokenPipeWriter struct{}
+
+func (brokenPipeWriter) Write(p []byte) (int, error) {
+	return 0, syscall.EPIPE
+}
+
+// TestProcessResponse_WriteError tests that write errors stop the processing.
+func TestProcessResponse_WriteError(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	input := `{"response": "Hello", "done": false}
+{"response": " World", "done": true, "context": [1]}`
+	contextSaved := false
+	err := ProcessResponse(strings.NewReader(input), brokenPipeWriter{}, func([]int) error {
+		contextSaved = true
+		return nil
+	})
+	if !errors.Is(err, syscall.EPIPE) {
+		t.Errorf("ProcessResponse() error = %v, want EPIPE", err)
+	}
+	if contextSaved {
+		t.Error("Did not expect the context to be saved after a write error")
+	}
+
+	chatInput := `{"message": {"role": "assistant", "content": "Hello"}, "done": false}
+{"message": {"role": "assistant", "content": " World"}, "done": true}`
+	message, err := ProcessChatResponse(strings.NewReader(chatInput), brokenPipeWriter{})
+	if !errors.Is(err, syscall.EPIPE) {
+		t.Errorf("ProcessChatResponse() error = %v, want EPIPE", err)
+	}
+	if message.Content != "Hello" {
+		t.Errorf("ProcessChatResponse() content = %q, want the content read before the error", message.Content)
+	}
+}
@@ -0,0 +1,80 @@
+package project
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// DirName is the directory holding the settings and prompts of a project
+const DirName = ".nino"
+
+// configFiles are the configuration files of a project, by preference
+var configFiles = []string{
+	".nino.toml",
+	".nino.yaml",
+	".nino.yml",
+	filepath.Join(DirName, "config.toml"),
+	filepath.Join(DirName, "config.yaml"),
+	filepath.Join(DirName, "config.yml"),
+}
+
+// invalidChars matches the characters replaced in the project IDs.
+var invalidChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Project is a directory with nino settings.
+type Project struct {
+	Root       string // Directory holding the .nino.toml file or the .nino directory
+	ConfigFile string // Configuration file of the project, or "" when there is none
+}
+
+// Find returns the project of dir: the nearest directory, from dir up to the git
+// root or the home directory (excluded), holding a .nino.toml (or .nino.yaml)
+// file or a .nino directory. It returns nil when there is none.
+func Find(dir string) *Project {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	home, _ := os.UserHomeDir()
+	for {
+		if home != "" && dir == filepath.Clean(home) {
+			return nil
+		}
+		if p := probe(dir); p != nil {
+			return p
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// probe returns the project rooted at dir, or nil if dir isn't the root of a project.
+func probe(dir string) *Project {
+	for _, name := range configFiles {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return &Project{Root: dir, ConfigFile: path}
+		}
+	}
+	if info, err := os.Stat(filepath.Join(dir, DirName)); err == nil && info.IsDir() {
+		return &Project{Root: dir}
+	}
+	return nil
+}
+
+// ID returns the name of the project in the data directory: the name of its root
+// directory followed by a hash of its path, so that projects with the same name
+// don't share their data.
+func (p *Project) ID() string {
+	sum := sha256.Sum256([]byte(p.Root))
+	return fmt.Sprintf("%s-%x", invalidChars.ReplaceAllString(filepath.Base(p.Root), "_"), sum[:4])
+}
@@ -0,0 +1,94 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("HOME", filepath.Join(base, "home"))
+
+	// Create the files of the directory tree, directories end with a slash
+	for _, name := range []string{
+		"home/.nino.toml",
+		"home/notes/",
+		"outer/.nino.toml",
+		"outer/repo/.git/",
+		"outer/repo/src/",
+		"work/.nino.toml",
+		"work/app/.nino/prompts/",
+		"work/app/cmd/",
+		"work/lib/.nino/config.yaml",
+		"work/lib/pkg/",
+		"work/docs/",
+	} {
+		path := filepath.Join(base, name)
+		if name[len(name)-1] == '/' {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		dir            string
+		wantRoot       string
+		wantConfigFile string
+	}{
+		{"Configuration file in a parent directory", "work/docs", "work", "work/.nino.toml"},
+		{"Nearest project directory", "work/app/cmd", "work/app", ""},
+		{"Configuration file in the project directory", "work/lib/pkg", "work/lib", "work/lib/.nino/config.yaml"},
+		{"Stops at the git root", "outer/repo/src", "", ""},
+		{"Stops at the home directory", "home/notes", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Find(filepath.Join(base, tt.dir))
+			if tt.wantRoot == "" {
+				if p != nil {
+					t.Errorf("Find() = %+v, want nil", p)
+				}
+				return
+			}
+			if p == nil {
+				t.Fatalf("Find() = nil, want the project at %s", tt.wantRoot)
+			}
+			if want := filepath.Join(base, tt.wantRoot); p.Root != want {
+				t.Errorf("Root = %s, want %s", p.Root, want)
+			}
+			wantConfigFile := ""
+			if tt.wantConfigFile != "" {
+				wantConfigFile = filepath.Join(base, tt.wantConfigFile)
+			}
+			if p.ConfigFile != wantConfigFile {
+				t.Errorf("ConfigFile = %q, want %q", p.ConfigFile, wantConfigFile)
+			}
+		})
+	}
+}
+
+func TestProjectID(t *testing.T) {
+	a := &Project{Root: "/home/dev/my app"}
+	b := &Project{Root: "/srv/my app"}
+
+	if !regexp.MustCompile(`^my_app-[0-9a-f]{8}$`).MatchString(a.ID()) {
+		t.Errorf("ID() = %s, want the sanitized name followed by a hash", a.ID())
+	}
+	if a.ID() != (&Project{Root: a.Root}).ID() {
+		t.Error("ID() is not stable")
+	}
+	if a.ID() == b.ID() {
+		t.Errorf("ID() = %s for both projects, want different IDs", a.ID())
+	}
+}
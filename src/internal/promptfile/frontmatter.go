@@ -0,0 +1,136 @@
+package promptfile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lucianoayres/nino-cli/internal/configfile"
+)
+
+// FrontMatter holds the settings of a prompt file header.
+type FrontMatter struct {
+	Description string // What the prompt does, shown in the prompt library
+	Model       string
+	System      string
+	Format      string                 // Output format, "json"
+	Schema      string                 // Path of the JSON schema file, relative to the prompt file
+	Options     map[string]interface{} // Ollama generation options
+	Images      []string               // Image paths, relative to the prompt file
+	KeepAlive   string
+}
+
+// frontMatterDelimiter opens and closes the front matter
+const frontMatterDelimiter = "---"
+
+// ParseFrontMatter splits a prompt file into its YAML front matter, delimited by
+// "---" lines at the top of the file, and the prompt. It returns a nil FrontMatter
+// when the file has no front matter.
+func ParseFrontMatter(content string) (*FrontMatter, string, error) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	first, rest, ok := strings.Cut(content, "\n")
+	if !ok || strings.TrimRight(first, " \r") != frontMatterDelimiter {
+		return nil, content, nil
+	}
+
+	// Find the closing delimiter
+	var header []string
+	for {
+		var line string
+		line, rest, ok = strings.Cut(rest, "\n")
+		trimmed := strings.TrimRight(line, " \r")
+		if trimmed == frontMatterDelimiter || trimmed == "..." {
+			break
+		}
+		if !ok {
+			return nil, "", fmt.Errorf("the front matter is not closed with a '---' line")
+		}
+		header = append(header, line)
+	}
+	if !ok {
+		rest = ""
+	}
+
+	values, err := configfile.ParseYAML(strings.Join(header, "\n"), 2)
+	if err != nil {
+		return nil, "", fmt.Errorf("error in front matter: %v", err)
+	}
+	fm, err := newFrontMatter(values)
+	if err != nil {
+		return nil, "", fmt.Errorf("error in front matter: %v", err)
+	}
+	return fm, rest, nil
+}
+
+// newFrontMatter converts the parsed header to a FrontMatter, rejecting unknown keys.
+func newFrontMatter(values map[string]interface{}) (*FrontMatter, error) {
+	fm := &FrontMatter{}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := values[key]
+		if value == nil {
+			continue
+		}
+		var err error
+		switch key {
+		case "description":
+			fm.Description, err = scalarString(key, value)
+		case "model":
+			fm.Model, err = scalarString(key, value)
+		case "system":
+			fm.System, err = scalarString(key, value)
+			fm.System = strings.TrimRight(fm.System, "\r\n")
+		case "format":
+			fm.Format, err = scalarString(key, value)
+		case "schema", "format_schema":
+			fm.Schema, err = scalarString(key, value)
+		case "keep_alive":
+			fm.KeepAlive, err = scalarString(key, value)
+		case "images":
+			fm.Images, err = stringList(key, value)
+		case "options":
+			options, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("'options' must be a mapping of option names to values")
+			}
+			fm.Options = options
+		default:
+			return nil, fmt.Errorf("unknown key '%s'", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fm, nil
+}
+
+// scalarString returns a scalar value as a string.
+func scalarString(key string, value interface{}) (string, error) {
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		return "", fmt.Errorf("'%s' must be a single value", key)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// stringList returns a value that is a scalar or a sequence of scalars as strings.
+func stringList(key string, value interface{}) ([]string, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		s, err := scalarString(key, item)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' must be a list of values", key)
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
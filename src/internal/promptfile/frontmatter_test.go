@@ -0,0 +1,87 @@
+package promptfile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		want       *FrontMatter
+		wantPrompt string
+		wantErr    bool
+	}{
+		{
+			name:       "No front matter",
+			content:    "What's the capital of Sweden?",
+			want:       nil,
+			wantPrompt: "What's the capital of Sweden?",
+		},
+		{
+			name: "All settings",
+			content: "---\ndescription: Release notes\nmodel: llama3.1\nsystem: |\n  You write release notes.\nformat: json\nschema: notes.schema.json\n" +
+				"keep_alive: 5m\nimages: [diagram.png]\noptions:\n  temperature: 0.2\n  stop: [END]\n---\nSummarize the changes.\n",
+			want: &FrontMatter{
+				Description: "Release notes",
+				Model:       "llama3.1",
+				System:      "You write release notes.",
+				Format:      "json",
+				Schema:      "notes.schema.json",
+				KeepAlive:   "5m",
+				Images:      []string{"diagram.png"},
+				Options:     map[string]interface{}{"temperature": 0.2, "stop": []interface{}{"END"}},
+			},
+			wantPrompt: "Summarize the changes.\n",
+		},
+		{
+			name:       "Windows line endings",
+			content:    "---\r\nmodel: mistral\r\n---\r\nHello",
+			want:       &FrontMatter{Model: "mistral"},
+			wantPrompt: "Hello",
+		},
+		{
+			name:       "Empty front matter",
+			content:    "---\n---\nHello",
+			want:       &FrontMatter{},
+			wantPrompt: "Hello",
+		},
+		{
+			name:    "Unclosed front matter",
+			content: "---\nmodel: mistral\nHello",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown key",
+			content: "---\nmodle: mistral\n---\nHello",
+			wantErr: true,
+		},
+		{
+			name:    "Options not a mapping",
+			content: "---\noptions: 1\n---\nHello",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, prompt, err := ParseFrontMatter(tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseFrontMatter() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFrontMatter() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFrontMatter() = %+v, want %+v", got, tt.want)
+			}
+			if prompt != tt.wantPrompt {
+				t.Errorf("ParseFrontMatter() prompt = %q, want %q", prompt, tt.wantPrompt)
+			}
+		})
+	}
+}
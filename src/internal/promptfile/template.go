@@ -0,0 +1,117 @@
+package promptfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Render renders a prompt written in Go's text/template syntax. The variables are
+// available as {{.name}} and using an undefined variable is an error. Files
+// included with {{include "path"}} are relative to dir.
+func Render(name, text string, vars map[string]string, dir string) (string, error) {
+	data := make(map[string]string, len(vars))
+	for key, value := range vars {
+		data[key] = value
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(builtins(dir)).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing prompt template: %v", err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("error rendering prompt template: %v", err)
+	}
+	return out.String(), nil
+}
+
+// builtins returns the functions available in prompt templates.
+func builtins(dir string) template.FuncMap {
+	return template.FuncMap{
+		// env returns an environment variable, failing if it is not set
+		"env": func(name string) (string, error) {
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				return "", fmt.Errorf("environment variable %s is not set", name)
+			}
+			return value, nil
+		},
+		// envOr returns an environment variable, or the fallback if it is not set
+		"envOr": func(name, fallback string) string {
+			if value, ok := os.LookupEnv(name); ok {
+				return value
+			}
+			return fallback
+		},
+		// date returns the current date, in the optional Go time layout
+		"date": func(layout ...string) string {
+			if len(layout) > 0 {
+				return time.Now().Format(layout[0])
+			}
+			return time.Now().Format("2006-01-02")
+		},
+		"now": time.Now,
+		"cwd": os.Getwd,
+		"os": func() string {
+			return runtime.GOOS
+		},
+		"arch": func() string {
+			return runtime.GOARCH
+		},
+		// gitBranch returns the current branch of the git repository in the working directory
+		"gitBranch": func() (string, error) {
+			branch, err := runCommand(exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD"))
+			if err != nil {
+				return "", fmt.Errorf("unable to determine the git branch: %v", err)
+			}
+			return branch, nil
+		},
+		// include returns the contents of a file, relative to the template
+		"include": func(path string) (string, error) {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return "", fmt.Errorf("error including file: %v", err)
+			}
+			return string(content), nil
+		},
+		// shell returns the output of a shell command, failing if the command fails
+		"shell": func(command string) (string, error) {
+			cmd := exec.Command("sh", "-c", command)
+			if runtime.GOOS == "windows" {
+				cmd = exec.Command("cmd", "/C", command)
+			}
+			output, err := runCommand(cmd)
+			if err != nil {
+				return "", fmt.Errorf("command '%s' failed: %v", command, err)
+			}
+			return output, nil
+		},
+	}
+}
+
+// runCommand runs a command and returns its output without the trailing newlines.
+// Errors include what the command wrote to stderr.
+func runCommand(cmd *exec.Cmd) (string, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
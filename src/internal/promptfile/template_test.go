@@ -0,0 +1,110 @@
+package promptfile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "style.md"), []byte("Be concise."), 0644); err != nil {
+		t.Fatalf("Failed to create temporary file: %v", err)
+	}
+	t.Setenv("NINO_TEST_TEAM", "platform")
+	os.Unsetenv("NINO_TEST_UNSET")
+
+	type renderTest struct {
+		name    string
+		text    string
+		vars    map[string]string
+		want    string
+		wantErr string
+	}
+	tests := []renderTest{
+		{
+			name: "Variables",
+			text: "Write release notes for {{.version}} of {{.project}}.",
+			vars: map[string]string{"version": "1.2.0", "project": "nino"},
+			want: "Write release notes for 1.2.0 of nino.",
+		},
+		{
+			name: "Plain text",
+			text: "What's the capital of Sweden?",
+			want: "What's the capital of Sweden?",
+		},
+		{
+			name: "Environment",
+			text: `Team {{env "NINO_TEST_TEAM"}}, owner {{envOr "NINO_TEST_UNSET" "nobody"}}`,
+			want: "Team platform, owner nobody",
+		},
+		{
+			name: "Platform",
+			text: "{{os}}/{{arch}}",
+			want: runtime.GOOS + "/" + runtime.GOARCH,
+		},
+		{
+			name: "Date",
+			text: `{{date}} {{date "2006"}} {{now.Year}}`,
+			want: time.Now().Format("2006-01-02") + " " + time.Now().Format("2006") + " " + time.Now().Format("2006"),
+		},
+		{
+			name: "Include",
+			text: `{{include "style.md"}}`,
+			want: "Be concise.",
+		},
+		{
+			name:    "Undefined variable",
+			text:    "Hello {{.name}}",
+			wantErr: `map has no entry for key "name"`,
+		},
+		{
+			name:    "Unset environment variable",
+			text:    `{{env "NINO_TEST_UNSET"}}`,
+			wantErr: "environment variable NINO_TEST_UNSET is not set",
+		},
+		{
+			name:    "Missing include",
+			text:    `{{include "missing.md"}}`,
+			wantErr: "error including file",
+		},
+		{
+			name:    "Syntax error",
+			text:    "{{.name",
+			wantErr: "error parsing prompt template",
+		},
+	}
+
+	if runtime.GOOS != "windows" {
+		tests = append(tests, renderTest{
+			name: "Shell",
+			text: `Status: {{shell "echo clean"}}`,
+			want: "Status: clean",
+		}, renderTest{
+			name:    "Failing shell command",
+			text:    `{{shell "echo broken >&2; exit 3"}}`,
+			wantErr: "broken",
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render("test", tt.text, tt.vars, dir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Render() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
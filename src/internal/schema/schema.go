@@ -0,0 +1,404 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON Schema. It supports the subset of JSON Schema used to
+// describe structured outputs: type, enum, const, properties, required,
+// additionalProperties, items, numeric and length limits, pattern, the allOf,
+// anyOf, oneOf and not combinators, and local $ref references.
+type Schema struct {
+	raw      json.RawMessage
+	root     interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+// ValidationError describes why a value does not conform to the schema.
+type ValidationError struct {
+	Path    string // Location of the invalid value, e.g. $.items[2].name
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Compile parses a JSON Schema document.
+func Compile(data []byte) (*Schema, error) {
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %v", err)
+	}
+	switch root.(type) {
+	case map[string]interface{}, bool:
+	default:
+		return nil, fmt.Errorf("invalid JSON schema: expected an object, got %s", typeOf(root))
+	}
+
+	s := &Schema{raw: append(json.RawMessage(nil), data...), root: root, patterns: make(map[string]*regexp.Regexp)}
+	if err := s.check(root, "#"); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %v", err)
+	}
+	return s, nil
+}
+
+// MarshalJSON returns the original schema document, so a Schema can be embedded in a request.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	return s.raw, nil
+}
+
+// check walks the schema, compiling patterns and verifying references.
+func (s *Schema) check(node interface{}, location string) error {
+	switch n := node.(type) {
+	case bool:
+		return nil
+	case map[string]interface{}:
+		if pattern, ok := n["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("%s/pattern: %v", location, err)
+			}
+			s.patterns[pattern] = re
+		}
+		if ref, ok := n["$ref"].(string); ok {
+			if _, err := s.resolve(ref); err != nil {
+				return fmt.Errorf("%s/$ref: %v", location, err)
+			}
+		}
+		for key, value := range n {
+			switch key {
+			case "enum", "const", "required", "default", "examples":
+				continue
+			}
+			if err := s.check(value, location+"/"+key); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, value := range n {
+			if err := s.check(value, location+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve returns the schema referenced by a local JSON pointer such as #/$defs/item.
+func (s *Schema) resolve(ref string) (interface{}, error) {
+	if ref != "#" && !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("only local references are supported, got '%s'", ref)
+	}
+	node := s.root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#"), "/")[1:] {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolvable reference '%s'", ref)
+		}
+		if node, ok = object[token]; !ok {
+			return nil, fmt.Errorf("unresolvable reference '%s'", ref)
+		}
+	}
+	return node, nil
+}
+
+// ValidateJSON checks that data is a JSON document conforming to the schema.
+func (s *Schema) ValidateJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return &ValidationError{Path: "$", Message: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	return s.Validate(value)
+}
+
+// Validate checks that a value decoded by encoding/json conforms to the schema.
+func (s *Schema) Validate(value interface{}) error {
+	return s.validate(s.root, value, "$", 0)
+}
+
+// maxDepth guards against reference cycles in the schema.
+const maxDepth = 256
+
+func (s *Schema) validate(node interface{}, value interface{}, path string, depth int) error {
+	if depth > maxDepth {
+		return &ValidationError{Path: path, Message: "schema nesting is too deep"}
+	}
+
+	n, ok := node.(map[string]interface{})
+	if !ok {
+		if node == false {
+			return &ValidationError{Path: path, Message: "no value is allowed here"}
+		}
+		return nil
+	}
+
+	if ref, ok := n["$ref"].(string); ok {
+		target, err := s.resolve(ref)
+		if err != nil {
+			return &ValidationError{Path: path, Message: err.Error()}
+		}
+		if err := s.validate(target, value, path, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if err := validateType(n, value, path); err != nil {
+		return err
+	}
+
+	if enum, ok := n["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if reflect.DeepEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("value %s is not one of %s", encode(value), encode(enum))}
+		}
+	}
+	if expected, ok := n["const"]; ok && !reflect.DeepEqual(expected, value) {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("expected %s, got %s", encode(expected), encode(value))}
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if err := validateNumber(n, v, path); err != nil {
+			return err
+		}
+	case string:
+		if err := s.validateString(n, v, path); err != nil {
+			return err
+		}
+	case []interface{}:
+		if err := s.validateArray(n, v, path, depth); err != nil {
+			return err
+		}
+	case map[string]interface{}:
+		if err := s.validateObject(n, v, path, depth); err != nil {
+			return err
+		}
+	}
+
+	return s.validateCombinators(n, value, path, depth)
+}
+
+// validateType checks the "type" keyword, which may be a single type or a list.
+func validateType(n map[string]interface{}, value interface{}, path string) error {
+	var types []string
+	switch t := n["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+	default:
+		return nil
+	}
+
+	actual := typeOf(value)
+	for _, expected := range types {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return nil
+		}
+	}
+	return &ValidationError{Path: path, Message: fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), actual)}
+}
+
+func validateNumber(n map[string]interface{}, v float64, path string) error {
+	if limit, ok := n["minimum"].(float64); ok && v < limit {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("%v is less than the minimum of %v", v, limit)}
+	}
+	if limit, ok := n["maximum"].(float64); ok && v > limit {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("%v is greater than the maximum of %v", v, limit)}
+	}
+	if limit, ok := n["exclusiveMinimum"].(float64); ok && v <= limit {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("%v must be greater than %v", v, limit)}
+	}
+	if limit, ok := n["exclusiveMaximum"].(float64); ok && v >= limit {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("%v must be less than %v", v, limit)}
+	}
+	if divisor, ok := n["multipleOf"].(float64); ok && divisor > 0 {
+		if q := v / divisor; q != math.Trunc(q) {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("%v is not a multiple of %v", v, divisor)}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateString(n map[string]interface{}, v string, path string) error {
+	length := utf8.RuneCountInString(v)
+	if limit, ok := n["minLength"].(float64); ok && float64(length) < limit {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("string is shorter than %v characters", limit)}
+	}
+	if limit, ok := n["maxLength"].(float64); ok && float64(length) > limit {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("string is longer than %v characters", limit)}
+	}
+	if pattern, ok := n["pattern"].(string); ok && !s.patterns[pattern].MatchString(v) {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("string %s does not match the pattern %s", encode(v), pattern)}
+	}
+	return nil
+}
+
+func (s *Schema) validateArray(n map[string]interface{}, v []interface{}, path string, depth int) error {
+	if limit, ok := n["minItems"].(float64); ok && float64(len(v)) < limit {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("expected at least %v items, got %d", limit, len(v))}
+	}
+	if limit, ok := n["maxItems"].(float64); ok && float64(len(v)) > limit {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("expected at most %v items, got %d", limit, len(v))}
+	}
+	if unique, ok := n["uniqueItems"].(bool); ok && unique {
+		for i := range v {
+			for j := i + 1; j < len(v); j++ {
+				if reflect.DeepEqual(v[i], v[j]) {
+					return &ValidationError{Path: path, Message: fmt.Sprintf("items %d and %d are identical", i, j)}
+				}
+			}
+		}
+	}
+	if items, ok := n["items"]; ok {
+		for i, item := range v {
+			if err := s.validate(items, item, fmt.Sprintf("%s[%d]", path, i), depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateObject(n map[string]interface{}, v map[string]interface{}, path string, depth int) error {
+	if required, ok := n["required"].([]interface{}); ok {
+		for _, item := range required {
+			if name, ok := item.(string); ok {
+				if _, present := v[name]; !present {
+					return &ValidationError{Path: path, Message: fmt.Sprintf("missing required property '%s'", name)}
+				}
+			}
+		}
+	}
+
+	// Visit the properties in a stable order so the reported error is deterministic
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	properties, _ := n["properties"].(map[string]interface{})
+	additional, hasAdditional := n["additionalProperties"]
+	for _, key := range keys {
+		propertyPath := path + "." + key
+		if property, ok := properties[key]; ok {
+			if err := s.validate(property, v[key], propertyPath, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+		if hasAdditional {
+			if additional == false {
+				return &ValidationError{Path: path, Message: fmt.Sprintf("property '%s' is not allowed", key)}
+			}
+			if err := s.validate(additional, v[key], propertyPath, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	if limit, ok := n["minProperties"].(float64); ok && float64(len(v)) < limit {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("expected at least %v properties, got %d", limit, len(v))}
+	}
+	if limit, ok := n["maxProperties"].(float64); ok && float64(len(v)) > limit {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("expected at most %v properties, got %d", limit, len(v))}
+	}
+	return nil
+}
+
+func (s *Schema) validateCombinators(n map[string]interface{}, value interface{}, path string, depth int) error {
+	if schemas, ok := n["allOf"].([]interface{}); ok {
+		for _, sub := range schemas {
+			if err := s.validate(sub, value, path, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	if schemas, ok := n["anyOf"].([]interface{}); ok {
+		var firstErr error
+		matched := false
+		for _, sub := range schemas {
+			err := s.validate(sub, value, path, depth+1)
+			if err == nil {
+				matched = true
+				break
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		if !matched && firstErr != nil {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("value does not match any of the allowed schemas (first mismatch: %v)", firstErr)}
+		}
+	}
+	if schemas, ok := n["oneOf"].([]interface{}); ok {
+		matches := 0
+		for _, sub := range schemas {
+			if s.validate(sub, value, path, depth+1) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("value must match exactly one schema, matched %d", matches)}
+		}
+	}
+	if sub, ok := n["not"]; ok {
+		if s.validate(sub, value, path, depth+1) == nil {
+			return &ValidationError{Path: path, Message: "value matches a schema it must not match"}
+		}
+	}
+	return nil
+}
+
+// typeOf returns the JSON Schema type name of a value decoded by encoding/json.
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// encode renders a value as compact JSON for error messages.
+func encode(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
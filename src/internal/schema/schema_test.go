@@ -0,0 +1,152 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const personSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"age": {"type": "integer", "minimum": 0},
+		"email": {"type": "string", "pattern": "^[^@]+@[^@]+$"},
+		"role": {"enum": ["admin", "user"]},
+		"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 3},
+		"address": {"$ref": "#/$defs/address"}
+	},
+	"required": ["name", "age"],
+	"additionalProperties": false,
+	"$defs": {
+		"address": {
+			"type": "object",
+			"properties": {"city": {"type": "string"}},
+			"required": ["city"]
+		}
+	}
+}`
+
+func TestValidateJSON(t *testing.T) {
+	s, err := Compile([]byte(personSchema))
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		wantPath string // Empty when the input is valid
+		wantMsg  string
+	}{
+		{"Valid minimal object", `{"name": "Ada", "age": 36}`, "", ""},
+		{"Valid full object", `{"name": "Ada", "age": 36, "email": "ada@example.com", "role": "admin", "tags": ["math"], "address": {"city": "London"}}`, "", ""},
+		{"Invalid JSON", `{"name": "Ada",`, "$", "invalid JSON"},
+		{"Wrong root type", `["Ada"]`, "$", "expected object, got array"},
+		{"Missing required property", `{"name": "Ada"}`, "$", "missing required property 'age'"},
+		{"Wrong property type", `{"name": "Ada", "age": "36"}`, "$.age", "expected integer, got string"},
+		{"Number instead of integer", `{"name": "Ada", "age": 36.5}`, "$.age", "expected integer, got number"},
+		{"Below minimum", `{"name": "Ada", "age": -1}`, "$.age", "less than the minimum"},
+		{"Empty string", `{"name": "", "age": 1}`, "$.name", "shorter than"},
+		{"Pattern mismatch", `{"name": "Ada", "age": 1, "email": "nope"}`, "$.email", "does not match the pattern"},
+		{"Not in enum", `{"name": "Ada", "age": 1, "role": "root"}`, "$.role", "is not one of"},
+		{"Array item type", `{"name": "Ada", "age": 1, "tags": ["a", 2]}`, "$.tags[1]", "expected string, got integer"},
+		{"Too many items", `{"name": "Ada", "age": 1, "tags": ["a", "b", "c", "d"]}`, "$.tags", "at most 3 items"},
+		{"Additional property", `{"name": "Ada", "age": 1, "extra": true}`, "$", "property 'extra' is not allowed"},
+		{"Reference", `{"name": "Ada", "age": 1, "address": {}}`, "$.address", "missing required property 'city'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ValidateJSON([]byte(tt.input))
+			if tt.wantPath == "" {
+				if err != nil {
+					t.Errorf("ValidateJSON() unexpected error: %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("ValidateJSON() error = %v, want a *ValidationError", err)
+			}
+			if validationErr.Path != tt.wantPath {
+				t.Errorf("ValidateJSON() path = %q, want %q", validationErr.Path, tt.wantPath)
+			}
+			if !strings.Contains(validationErr.Message, tt.wantMsg) {
+				t.Errorf("ValidateJSON() message = %q, want it to contain %q", validationErr.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestValidateCombinators(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		input   string
+		wantErr bool
+	}{
+		{"anyOf match", `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, `3`, false},
+		{"anyOf mismatch", `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, `true`, true},
+		{"oneOf exactly one", `{"oneOf": [{"type": "integer"}, {"type": "string"}]}`, `"x"`, false},
+		{"oneOf more than one", `{"oneOf": [{"type": "integer"}, {"type": "number"}]}`, `3`, true},
+		{"allOf", `{"allOf": [{"minimum": 1}, {"maximum": 5}]}`, `6`, true},
+		{"not", `{"not": {"type": "null"}}`, `null`, true},
+		{"const", `{"const": {"ok": true}}`, `{"ok": true}`, false},
+		{"Type list", `{"type": ["string", "null"]}`, `null`, false},
+		{"True schema", `true`, `{"anything": 1}`, false},
+		{"False schema", `false`, `1`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Compile([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("Compile returned error: %v", err)
+			}
+			if err := s.ValidateJSON([]byte(tt.input)); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"Invalid JSON", `{"type": `},
+		{"Not an object", `"string"`},
+		{"Invalid pattern", `{"pattern": "("}`},
+		{"Remote reference", `{"$ref": "https://example.com/schema.json"}`},
+		{"Unresolvable reference", `{"$ref": "#/$defs/missing"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile([]byte(tt.schema)); err == nil {
+				t.Errorf("Compile(%s) expected error, got nil", tt.schema)
+			}
+		})
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	s, err := Compile([]byte(`{"type": "object"}`))
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+
+	data, err := json.Marshal(struct {
+		Format *Schema `json:"format"`
+	}{s})
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %v", err)
+	}
+	if want := `{"format":{"type":"object"}}`; string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+}
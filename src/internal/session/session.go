@@ -0,0 +1,260 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lucianoayres/nino-cli/internal/fileutil"
+	"github.com/lucianoayres/nino-cli/internal/logger"
+	"github.com/lucianoayres/nino-cli/internal/models"
+	"github.com/lucianoayres/nino-cli/internal/utils"
+)
+
+// fileExtension is the extension of session files. Each line holds one JSON-encoded message.
+const fileExtension = ".jsonl"
+
+// validName matches the session names accepted by the store.
+var validName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// Info describes a stored session.
+type Info struct {
+	Name      string
+	Messages  int
+	UpdatedAt time.Time
+}
+
+// ValidateName checks that the session name can be safely used as a file name.
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid session name '%s': use letters, digits, '.', '-' and '_' only", name)
+	}
+	return nil
+}
+
+// getSessionsDir returns the directory where the session files are stored.
+func getSessionsDir() (string, error) {
+	dataDir, err := utils.GetNinoDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "sessions"), nil
+}
+
+// getSessionPath returns the file path of the given session.
+func getSessionPath(name string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	dir, err := getSessionsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+fileExtension), nil
+}
+
+// Load returns the message history of the given session.
+// It returns nil if the session does not exist yet.
+func Load(name string) ([]models.Message, error) {
+	log := logger.GetLogger(true) // Assuming logger is already initialized in main
+	log.Info("Loading session: %s", name)
+
+	path, err := getSessionPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Info("Session file does not exist: %s", path)
+			return nil, nil
+		}
+		log.Error("Failed to open session file: %v", err)
+		return nil, fmt.Errorf("failed to open session '%s': %v", name, err)
+	}
+	defer file.Close()
+
+	var messages []models.Message
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024) // Messages may carry base64 images
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var message models.Message
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			log.Error("Failed to decode session message: %v", err)
+			return nil, fmt.Errorf("failed to decode session '%s' at line %d: %v", name, line, err)
+		}
+		messages = append(messages, message)
+	}
+	if err := scanner.Err(); err != nil {
+		log.Error("Failed to read session file: %v", err)
+		return nil, fmt.Errorf("failed to read session '%s': %v", name, err)
+	}
+
+	log.Info("Loaded %d message(s) from session %s", len(messages), name)
+	return messages, nil
+}
+
+// Append adds the given messages to the end of the session log, creating the session if needed.
+func Append(name string, messages ...models.Message) error {
+	log := logger.GetLogger(true) // Assuming logger is already initialized in main
+	log.Info("Appending %d message(s) to session: %s", len(messages), name)
+
+	path, err := getSessionPath(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Error("Failed to create directories: %v", err)
+		return fmt.Errorf("failed to create directories: %v", err)
+	}
+
+	// Encode all messages first so they are written with a single call
+	data, err := encodeMessages(messages)
+	if err != nil {
+		log.Error("Failed to encode session message: %v", err)
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Error("Failed to open session file: %v", err)
+		return fmt.Errorf("failed to open session '%s': %v", name, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		log.Error("Failed to write session file: %v", err)
+		return fmt.Errorf("failed to write session '%s': %v", name, err)
+	}
+
+	log.Info("Session %s updated successfully", name)
+	return nil
+}
+
+// Save replaces the message history of the given session, creating the session if needed.
+func Save(name string, messages []models.Message) error {
+	log := logger.GetLogger(true) // Assuming logger is already initialized in main
+	log.Info("Saving %d message(s) to session: %s", len(messages), name)
+
+	path, err := getSessionPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Error("Failed to create directories: %v", err)
+		return fmt.Errorf("failed to create directories: %v", err)
+	}
+
+	data, err := encodeMessages(messages)
+	if err != nil {
+		log.Error("Failed to encode session message: %v", err)
+		return err
+	}
+	if err := fileutil.WriteFileAtomic(path, data, 0644); err != nil {
+		log.Error("Failed to write session file: %v", err)
+		return fmt.Errorf("failed to write session '%s': %v", name, err)
+	}
+
+	log.Info("Session %s saved successfully", name)
+	return nil
+}
+
+// encodeMessages encodes the messages as JSON, one per line.
+func encodeMessages(messages []models.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, message := range messages {
+		if err := encoder.Encode(message); err != nil {
+			return nil, fmt.Errorf("failed to encode session message: %v", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// List returns the stored sessions sorted by name.
+func List() ([]Info, error) {
+	dir, err := getSessionsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read sessions directory: %v", err)
+	}
+
+	var sessions []Info
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), fileExtension)
+		if entry.IsDir() || !ok || ValidateName(name) != nil {
+			continue
+		}
+		messages, err := Load(name)
+		if err != nil {
+			return nil, err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat session '%s': %v", name, err)
+		}
+		sessions = append(sessions, Info{Name: name, Messages: len(messages), UpdatedAt: info.ModTime()})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Name < sessions[j].Name })
+	return sessions, nil
+}
+
+// Remove deletes the given session.
+func Remove(name string) error {
+	path, err := getSessionPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("session '%s' does not exist", name)
+		}
+		return fmt.Errorf("failed to remove session '%s': %v", name, err)
+	}
+	return nil
+}
+
+// Rename renames a session, refusing to overwrite an existing one.
+func Rename(oldName, newName string) error {
+	oldPath, err := getSessionPath(oldName)
+	if err != nil {
+		return err
+	}
+	newPath, err := getSessionPath(newName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(oldPath); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("session '%s' does not exist", oldName)
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return fmt.Errorf("session '%s' already exists", newName)
+	}
+
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return fmt.Errorf("failed to rename session '%s' to '%s': %v", oldName, newName, err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lucianoayres/nino-cli/internal/logger"
+	"github.com/lucianoayres/nino-cli/internal/models"
+)
+
+// TestAppendAndLoad tests that appended messages are replayed in order.
+func TestAppendAndLoad(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	// Use a temporary directory as XDG_DATA_HOME
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	first := []models.Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "assistant", Content: "Hi! How can I help?"},
+	}
+	second := []models.Message{
+		{Role: "user", Content: "Describe this", Images: []string{"aW1hZ2U="}},
+		{Role: "assistant", Content: "A cat.\nSleeping."},
+	}
+
+	if err := Append("work", first...); err != nil {
+		t.Fatalf("Append returned error: %v", err)
+	}
+	if err := Append("work", second...); err != nil {
+		t.Fatalf("Append returned error on second call: %v", err)
+	}
+
+	messages, err := Load("work")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	want := append(first, second...)
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("Loaded messages do not match.\nExpected: %v\nGot: %v", want, messages)
+	}
+
+	// Other sessions are not affected
+	other, err := Load("personal")
+	if err != nil {
+		t.Fatalf("Load returned error for missing session: %v", err)
+	}
+	if other != nil {
+		t.Errorf("Expected nil history for missing session, got: %v", other)
+	}
+}
+
+// TestSave tests that saving a session replaces its history.
+func TestSave(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	// Use a temporary directory as XDG_DATA_HOME
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	if err := Append("work", models.Message{Role: "user", Content: "Hello"}, models.Message{Role: "assistant", Content: "Hi!"}); err != nil {
+		t.Fatalf("Append returned error: %v", err)
+	}
+	want := []models.Message{{Role: "user", Content: "Hello"}}
+	if err := Save("work", want); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	messages, err := Load("work")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("Loaded messages do not match.\nExpected: %v\nGot: %v", want, messages)
+	}
+
+	if err := Save("../escape", want); err == nil {
+		t.Error("Expected an error for an invalid session name")
+	}
+}
+
+// TestListRemoveRename tests the session management functions.
+func TestListRemoveRename(t *testing.T) {
+	// Initialize logger for tests
+	logger.GetLogger(true)
+
+	// Use a temporary directory as XDG_DATA_HOME
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	sessions, err := List()
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("Expected no sessions, got: %v", sessions)
+	}
+
+	Append("beta", models.Message{Role: "user", Content: "b"})
+	Append("alpha", models.Message{Role: "user", Content: "a"}, models.Message{Role: "assistant", Content: "a"})
+
+	sessions, err = List()
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].Name != "alpha" || sessions[1].Name != "beta" {
+		t.Fatalf("Unexpected sessions: %v", sessions)
+	}
+	if sessions[0].Messages != 2 {
+		t.Errorf("Expected 2 messages in alpha, got: %d", sessions[0].Messages)
+	}
+
+	if err := Rename("alpha", "beta"); err == nil {
+		t.Error("Expected error when renaming onto an existing session")
+	}
+	if err := Rename("alpha", "gamma"); err != nil {
+		t.Fatalf("Rename returned error: %v", err)
+	}
+	if err := Rename("missing", "delta"); err == nil {
+		t.Error("Expected error when renaming a missing session")
+	}
+
+	if err := Remove("beta"); err != nil {
+		t.Fatalf("Remove returned error: %v", err)
+	}
+	if err := Remove("beta"); err == nil {
+		t.Error("Expected error when removing a missing session")
+	}
+
+	sessions, _ = List()
+	if len(sessions) != 1 || sessions[0].Name != "gamma" {
+		t.Errorf("Expected only gamma to remain, got: %v", sessions)
+	}
+}
+
+// TestValidateName tests the ValidateName function with various inputs.
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"work", false},
+		{"project-x_2.1", false},
+		{"", true},
+		{".hidden", true},
+		{"../escape", true},
+		{"with space", true},
+		{"a/b", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateName(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateName(%q) error = %v; wantErr %v", tt.input, err, tt.wantErr)
+		}
+	}
+}
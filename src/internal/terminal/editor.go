@@ -0,0 +1,269 @@
+package terminal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// ErrInterrupted is returned by ReadLine when Ctrl-C is pressed.
+var ErrInterrupted = errors.New("interrupted")
+
+// Editor reads lines from the terminal with line editing and history. When the
+// input is not a terminal, the lines are read as they come.
+type Editor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	fd       int
+	terminal bool
+	history  *History
+}
+
+// NewEditor returns an editor reading from in and echoing to out. The history may be nil.
+func NewEditor(in *os.File, out io.Writer, history *History) *Editor {
+	fd := int(in.Fd())
+	return &Editor{
+		in:       bufio.NewReader(in),
+		out:      out,
+		fd:       fd,
+		terminal: IsTerminal(fd),
+		history:  history,
+	}
+}
+
+// Terminal reports whether the lines are read from a terminal.
+func (e *Editor) Terminal() bool {
+	return e.terminal
+}
+
+// ReadLine shows the prompt and reads a line. It returns ErrInterrupted when Ctrl-C
+// is pressed, and io.EOF when Ctrl-D is pressed on an empty line or the input ends.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if !e.terminal {
+		line, err := e.in.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		return strings.TrimRight(line, "\r\n"), err
+	}
+
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	var history []string
+	if e.history != nil {
+		history = e.history.Entries()
+	}
+	return editLine(e.in, e.out, prompt, history)
+}
+
+// line is the state of the line being edited.
+type line struct {
+	w      io.Writer
+	prompt string
+	buf    []rune
+	pos    int
+}
+
+// refresh redraws the prompt and the line, and moves the cursor to its position.
+func (l *line) refresh() {
+	fmt.Fprintf(l.w, "\r%s%s\033[K", l.prompt, string(l.buf))
+	if n := len(l.buf) - l.pos; n > 0 {
+		fmt.Fprintf(l.w, "\033[%dD", n)
+	}
+}
+
+func (l *line) insert(r rune) {
+	l.buf = append(l.buf[:l.pos], append([]rune{r}, l.buf[l.pos:]...)...)
+	l.pos++
+}
+
+func (l *line) set(text []rune) {
+	l.buf = append([]rune(nil), text...)
+	l.pos = len(l.buf)
+}
+
+// deleteRange deletes the runes from start to end.
+func (l *line) deleteRange(start, end int) {
+	l.buf = append(l.buf[:start], l.buf[end:]...)
+	l.pos = start
+}
+
+// wordStart returns the position of the start of the word before the cursor.
+func (l *line) wordStart() int {
+	i := l.pos
+	for i > 0 && unicode.IsSpace(l.buf[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(l.buf[i-1]) {
+		i--
+	}
+	return i
+}
+
+// ctrl returns the character sent by the terminal for Ctrl and the key.
+func ctrl(key byte) rune {
+	return rune(key & 0x1f)
+}
+
+// Keys decoded from the escape sequences
+const (
+	keyNone = iota
+	keyUp
+	keyDown
+	keyRight
+	keyLeft
+	keyHome
+	keyEnd
+	keyDelete
+)
+
+// editLine reads the keys from r until Enter is pressed, editing the line and
+// echoing it to w. The up and down keys browse the history.
+func editLine(r *bufio.Reader, w io.Writer, prompt string, history []string) (string, error) {
+	l := &line{w: w, prompt: prompt}
+	l.refresh()
+
+	historyIndex := len(history)
+	var pending []rune // The line being edited while browsing the history
+	browse := func(delta int) {
+		index := historyIndex + delta
+		if index < 0 || index > len(history) {
+			return
+		}
+		if historyIndex == len(history) {
+			pending = append([]rune(nil), l.buf...)
+		}
+		historyIndex = index
+		if index == len(history) {
+			l.set(pending)
+		} else {
+			l.set([]rune(history[index]))
+		}
+	}
+
+	for {
+		c, _, err := r.ReadRune()
+		if err != nil {
+			if err == io.EOF && len(l.buf) > 0 {
+				fmt.Fprint(w, "\r\n")
+				return string(l.buf), nil
+			}
+			return "", err
+		}
+
+		key := keyNone
+		switch c {
+		case '\r', '\n':
+			fmt.Fprint(w, "\r\n")
+			return string(l.buf), nil
+		case ctrl('C'):
+			fmt.Fprint(w, "^C\r\n")
+			return "", ErrInterrupted
+		case ctrl('D'):
+			if len(l.buf) == 0 {
+				fmt.Fprint(w, "\r\n")
+				return "", io.EOF
+			}
+			key = keyDelete
+		case 0x7f, ctrl('H'):
+			if l.pos > 0 {
+				l.deleteRange(l.pos-1, l.pos)
+			}
+		case ctrl('A'):
+			key = keyHome
+		case ctrl('E'):
+			key = keyEnd
+		case ctrl('B'):
+			key = keyLeft
+		case ctrl('F'):
+			key = keyRight
+		case ctrl('P'):
+			key = keyUp
+		case ctrl('N'):
+			key = keyDown
+		case ctrl('K'):
+			l.buf = l.buf[:l.pos]
+		case ctrl('U'):
+			l.deleteRange(0, l.pos)
+		case ctrl('W'):
+			l.deleteRange(l.wordStart(), l.pos)
+		case ctrl('L'):
+			fmt.Fprint(w, "\033[H\033[2J")
+		case 0x1b:
+			key = readEscape(r)
+		default:
+			if unicode.IsPrint(c) {
+				l.insert(c)
+			}
+		}
+
+		switch key {
+		case keyUp:
+			browse(-1)
+		case keyDown:
+			browse(1)
+		case keyLeft:
+			l.pos = max(l.pos-1, 0)
+		case keyRight:
+			l.pos = min(l.pos+1, len(l.buf))
+		case keyHome:
+			l.pos = 0
+		case keyEnd:
+			l.pos = len(l.buf)
+		case keyDelete:
+			if l.pos < len(l.buf) {
+				l.deleteRange(l.pos, l.pos+1)
+			}
+		}
+		l.refresh()
+	}
+}
+
+// readEscape reads the rest of an escape sequence and returns the key it encodes,
+// or keyNone for the sequences that are ignored.
+func readEscape(r *bufio.Reader) int {
+	introducer, err := r.ReadByte()
+	if err != nil || (introducer != '[' && introducer != 'O') {
+		return keyNone
+	}
+	// Control sequences end with a byte in the range @ to ~, after optional parameters
+	var params []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return keyNone
+		}
+		if b >= 0x40 && b <= 0x7e {
+			switch {
+			case b == 'A':
+				return keyUp
+			case b == 'B':
+				return keyDown
+			case b == 'C':
+				return keyRight
+			case b == 'D':
+				return keyLeft
+			case b == 'H':
+				return keyHome
+			case b == 'F':
+				return keyEnd
+			case b == '~' && (string(params) == "1" || string(params) == "7"):
+				return keyHome
+			case b == '~' && (string(params) == "4" || string(params) == "8"):
+				return keyEnd
+			case b == '~' && string(params) == "3":
+				return keyDelete
+			}
+			return keyNone
+		}
+		params = append(params, b)
+	}
+}
@@ -0,0 +1,67 @@
+package terminal
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestEditLine(t *testing.T) {
+	history := []string{"first", "second"}
+
+	tests := []struct {
+		name    string
+		keys    string
+		want    string
+		wantErr error
+	}{
+		{name: "Plain line", keys: "hello\r", want: "hello"},
+		{name: "Line feed ends the line", keys: "hello\n", want: "hello"},
+		{name: "Unicode", keys: "héllo wörld\r", want: "héllo wörld"},
+		{name: "Backspace", keys: "helxy\x7f\x7flo\r", want: "hello"},
+		{name: "Insert after moving left", keys: "hllo\x1b[D\x1b[D\x1b[De\r", want: "hello"},
+		{name: "Home and end", keys: "ello\x01h\x05!\r", want: "hello!"},
+		{name: "Home and end sequences", keys: "ello\x1b[Hh\x1b[F!\r", want: "hello!"},
+		{name: "Delete under the cursor", keys: "hxello\x01\x1b[C\x1b[3~\r", want: "hello"},
+		{name: "Ctrl-D deletes in a line", keys: "hxello\x01\x06\x04\r", want: "hello"},
+		{name: "Kill to the end", keys: "hello world\x01\x1b[C\x1b[C\x1b[C\x1b[C\x1b[C\x0b\r", want: "hello"},
+		{name: "Kill to the start", keys: "hello world\x15bye\r", want: "bye"},
+		{name: "Delete the previous word", keys: "hello big  \x17world\r", want: "hello world"},
+		{name: "Previous history entry", keys: "\x1b[A\r", want: "second"},
+		{name: "Oldest history entry", keys: "\x1b[A\x1b[A\x1b[A\r", want: "first"},
+		{name: "Back to the edited line", keys: "draft\x10\x10\x0e\x0e\r", want: "draft"},
+		{name: "Edit a history entry", keys: "\x1bOA!\r", want: "second!"},
+		{name: "Ignored sequence", keys: "a\x1b[200~b\x1b[1;5Cc\r", want: "abc"},
+		{name: "Control characters are ignored", keys: "a\tb\x07\r", want: "ab"},
+		{name: "Ctrl-C", keys: "hello\x03", wantErr: ErrInterrupted},
+		{name: "Ctrl-D on an empty line", keys: "\x04", wantErr: io.EOF},
+		{name: "End of input after text", keys: "hello", want: "hello"},
+		{name: "End of input", keys: "", wantErr: io.EOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			got, err := editLine(bufio.NewReader(strings.NewReader(tt.keys)), &out, "> ", history)
+			if err != tt.wantErr {
+				t.Fatalf("editLine() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("editLine() = %q, want %q", got, tt.want)
+			}
+			if !strings.HasPrefix(out.String(), "\r> ") {
+				t.Errorf("editLine() output = %q, want the prompt first", out.String())
+			}
+		})
+	}
+}
+
+func TestLineRefresh(t *testing.T) {
+	var out strings.Builder
+	l := &line{w: &out, prompt: ">>> ", buf: []rune("héllo"), pos: 2}
+	l.refresh()
+	if want := "\r>>> héllo\033[K\033[3D"; out.String() != want {
+		t.Errorf("refresh() = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,94 @@
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lucianoayres/nino-cli/internal/fileutil"
+)
+
+// maxHistory is the number of lines kept in the history.
+const maxHistory = 1000
+
+// History holds the lines entered, saved one per line in a file.
+type History struct {
+	path    string
+	entries []string
+}
+
+// LoadHistory loads the history saved in the file, which doesn't need to exist.
+// An empty path gives a history that isn't saved.
+func LoadHistory(path string) (*History, error) {
+	h := &History{path: path}
+	if path == "" {
+		return h, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading history '%s': %v", path, err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.TrimSpace(line) != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history '%s': %v", path, err)
+	}
+
+	// Rewrite the file when it grew past the limit
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[len(h.entries)-maxHistory:]
+		data := strings.Join(h.entries, "\n") + "\n"
+		if err := fileutil.WriteFileAtomic(path, []byte(data), 0600); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// Add adds a line to the history and appends it to the file. Blank lines and
+// repeats of the previous line are skipped.
+func (h *History) Add(line string) error {
+	if strings.TrimSpace(line) == "" || strings.ContainsAny(line, "\r\n") {
+		return nil
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == line {
+		return nil
+	}
+	h.entries = append(h.entries, line)
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[1:]
+	}
+	if h.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory '%s': %v", filepath.Dir(h.path), err)
+	}
+	file, err := os.OpenFile(h.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("error opening history '%s': %v", h.path, err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(line + "\n"); err != nil {
+		return fmt.Errorf("error writing history '%s': %v", h.path, err)
+	}
+	return nil
+}
+
+// Entries returns the lines of the history, from the oldest.
+func (h *History) Entries() []string {
+	return h.entries
+}
@@ -0,0 +1,73 @@
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nino", "history")
+
+	h, err := LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory() unexpected error: %v", err)
+	}
+	for _, line := range []string{"hello", "hello", "  ", "/model llama3.1", "multi\nline", "bye"} {
+		if err := h.Add(line); err != nil {
+			t.Fatalf("Add(%q) unexpected error: %v", line, err)
+		}
+	}
+	want := []string{"hello", "/model llama3.1", "bye"}
+	if !reflect.DeepEqual(h.Entries(), want) {
+		t.Errorf("Entries() = %q, want %q", h.Entries(), want)
+	}
+
+	reloaded, err := LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(reloaded.Entries(), want) {
+		t.Errorf("reloaded Entries() = %q, want %q", reloaded.Entries(), want)
+	}
+}
+
+func TestLoadHistory_Trim(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	var lines []string
+	for i := 0; i < maxHistory+10; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory() unexpected error: %v", err)
+	}
+	entries := h.Entries()
+	if len(entries) != maxHistory || entries[0] != "line 10" {
+		t.Errorf("LoadHistory() kept %d entries from %q, want %d from %q", len(entries), entries[0], maxHistory, "line 10")
+	}
+	content, _ := os.ReadFile(path)
+	if got := strings.Count(string(content), "\n"); got != maxHistory {
+		t.Errorf("history file has %d lines, want %d", got, maxHistory)
+	}
+}
+
+func TestLoadHistory_NotSaved(t *testing.T) {
+	h, err := LoadHistory("")
+	if err != nil {
+		t.Fatalf("LoadHistory() unexpected error: %v", err)
+	}
+	if err := h.Add("hello"); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(h.Entries(), []string{"hello"}) {
+		t.Errorf("Entries() = %q, want [hello]", h.Entries())
+	}
+}
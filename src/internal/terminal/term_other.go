@@ -0,0 +1,16 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package terminal
+
+import "errors"
+
+// IsTerminal reports whether the file descriptor is a terminal. Terminals are
+// not detected on this platform, so the input is read line by line.
+func IsTerminal(fd int) bool {
+	return false
+}
+
+// makeRaw is not supported on this platform.
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
@@ -17,26 +17,27 @@ func ShowLoadingAnimation(done chan bool) {
 	}
 	resetColor := "\033[0m"
 
-	for {
+	// Check for done at every frame, so the animation stops as soon as the response arrives
+	ticker := time.NewTicker(150 * time.Millisecond)
+	defer ticker.Stop()
+	for waveStart := 0; ; waveStart = (waveStart + 1) % (len(loadingText) + len(shades)) {
+		// Create a wave effect by iterating over each character and applying shades
+		fmt.Printf("\r")
+		for i := 0; i < len(loadingText); i++ {
+			shadeOffset := waveStart - i
+			if shadeOffset >= 0 && shadeOffset < len(shades) {
+				fmt.Printf("%s%c%s", shades[len(shades)-1-shadeOffset], loadingText[i], resetColor)
+			} else {
+				fmt.Printf("%s%c%s", shades[0], loadingText[i], resetColor)
+			}
+		}
+
 		select {
 		case <-done:
 			// Clear the animation before stopping
 			fmt.Print("\r\033[K")
 			return
-		default:
-			// Create a wave effect by iterating over each character and applying shades
-			for waveStart := 0; waveStart < len(loadingText)+len(shades); waveStart++ {
-				fmt.Printf("\r")
-				for i := 0; i < len(loadingText); i++ {
-					shadeOffset := waveStart - i
-					if shadeOffset >= 0 && shadeOffset < len(shades) {
-						fmt.Printf("%s%c%s", shades[len(shades)-1-shadeOffset], loadingText[i], resetColor)
-					} else {
-						fmt.Printf("%s%c%s", shades[0], loadingText[i], resetColor)
-					}
-				}
-				time.Sleep(150 * time.Millisecond)
-			}
+		case <-ticker.C:
 		}
 	}
 }
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// GetConfigDir returns the configuration directory, checking XDG_CONFIG_HOME first.
+func GetConfigDir() (string, error) {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to determine home directory: %v", err)
+		}
+		configDir = filepath.Join(homeDir, ".config")
+	}
+	return configDir, nil
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetConfigDir(t *testing.T) {
+	t.Run("Uses XDG_CONFIG_HOME when set", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg-config")
+
+		configDir, err := GetConfigDir()
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if configDir != "/tmp/xdg-config" {
+			t.Errorf("Expected /tmp/xdg-config, got: %s", configDir)
+		}
+	})
+
+	t.Run("Falls back to ~/.config", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", "")
+
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			t.Skipf("Home directory not available: %v", err)
+		}
+
+		configDir, err := GetConfigDir()
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if want := filepath.Join(homeDir, ".config"); configDir != want {
+			t.Errorf("Expected %s, got: %s", want, configDir)
+		}
+	})
+}
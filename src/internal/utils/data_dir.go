@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// GetDataDir returns the data directory, checking XDG_DATA_HOME first.
+func GetDataDir() (string, error) {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to determine home directory: %v", err)
+		}
+		dataDir = filepath.Join(homeDir, ".local", "share")
+	}
+	return dataDir, nil
+}
+
+// dataScope is the ID of the project the contexts and sessions are scoped to, "" when global
+var dataScope string
+
+// SetDataScope scopes the contexts and sessions to the project with the given ID,
+// or makes them global again when the ID is empty.
+func SetDataScope(projectID string) {
+	dataScope = projectID
+}
+
+// GetNinoDataDir returns the directory of the contexts and sessions: nino in the data
+// directory, or the directory of the project they are scoped to below it.
+func GetNinoDataDir() (string, error) {
+	dataDir, err := GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(dataDir, "nino")
+	if dataScope != "" {
+		dir = filepath.Join(dir, "projects", dataScope)
+	}
+	return dir, nil
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetDataDir(t *testing.T) {
+	t.Run("Uses XDG_DATA_HOME when set", func(t *testing.T) {
+		t.Setenv("XDG_DATA_HOME", "/tmp/xdg-data")
+
+		dataDir, err := GetDataDir()
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if dataDir != "/tmp/xdg-data" {
+			t.Errorf("Expected /tmp/xdg-data, got: %s", dataDir)
+		}
+	})
+
+	t.Run("Falls back to ~/.local/share", func(t *testing.T) {
+		t.Setenv("XDG_DATA_HOME", "")
+
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			t.Skipf("Home directory not available: %v", err)
+		}
+
+		dataDir, err := GetDataDir()
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if want := filepath.Join(homeDir, ".local", "share"); dataDir != want {
+			t.Errorf("Expected %s, got: %s", want, dataDir)
+		}
+	})
+}
+
+func TestGetNinoDataDir(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/tmp/xdg-data")
+	t.Cleanup(func() { SetDataScope("") })
+
+	tests := []struct {
+		name  string
+		scope string
+		want  string
+	}{
+		{"Global", "", "/tmp/xdg-data/nino"},
+		{"Scoped to a project", "app-0123abcd", "/tmp/xdg-data/nino/projects/app-0123abcd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetDataScope(tt.scope)
+			dir, err := GetNinoDataDir()
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if want := filepath.FromSlash(tt.want); dir != want {
+				t.Errorf("Expected %s, got: %s", want, dir)
+			}
+		})
+	}
+}
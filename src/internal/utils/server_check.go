@@ -1,36 +1,50 @@
 package utils
 
 import (
-	"net"
+	"errors"
+	"net/http"
 	"net/url"
 	"time"
 
+	"github.com/lucianoayres/nino-cli/internal/client"
 	"github.com/lucianoayres/nino-cli/internal/logger"
 )
 
-// IsOllamaRunning checks if the Ollama server is running at the specified URL
-func IsOllamaRunning(urlStr string) bool {
+// serverCheckTimeout bounds how long the server check waits for a response
+const serverCheckTimeout = 2 * time.Second
+
+// IsOllamaRunning checks if the Ollama server is running at the specified URL,
+// sending the given headers
+func IsOllamaRunning(urlStr string, headers map[string]string) bool {
+	_, err := CheckOllamaServer(urlStr, headers)
+	return err == nil
+}
+
+// CheckOllamaServer asks the server at the specified URL for its version, so that
+// other processes listening on the port (or a proxy returning an error) don't pass
+// as a running Ollama server. It returns the version of the server.
+func CheckOllamaServer(urlStr string, headers map[string]string) (string, error) {
 	log := logger.GetLogger(true) // Assuming logger is already initialized in main
 	log.Info("Checking if Ollama server is running at URL: %s", urlStr)
 
 	u, err := url.Parse(urlStr)
 	if err != nil {
 		log.Error("URL parsing error: %v", err)
-		return false
+		return "", err
 	}
-	host := u.Host
-	if host == "" {
+	if u.Host == "" {
 		log.Error("Empty host in URL")
-		return false
+		return "", errors.New("the URL has no host")
 	}
-	// Try to establish a TCP connection to the host and port
-	log.Info("Attempting TCP connection to %s", host)
-	conn, err := net.DialTimeout("tcp", host, 2*time.Second)
+
+	cli := client.NewHTTPClient(urlStr)
+	cli.HTTPClient = &http.Client{Timeout: serverCheckTimeout}
+	cli.Headers = headers
+	version, err := cli.Version()
 	if err != nil {
-		log.Error("TCP connection failed: %v", err)
-		return false
+		log.Error("Server check failed: %v", err)
+		return "", err
 	}
-	conn.Close()
-	log.Info("TCP connection successful")
-	return true
+	log.Info("Ollama server version %s is running", version)
+	return version, nil
 }
@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/lucianoayres/nino-cli/internal/logger"
@@ -12,50 +15,92 @@ func TestIsOllamaRunning(t *testing.T) {
 	logger.GetLogger(true)
 
 	tests := []struct {
-		name   string
-		urlStr string
-		want   bool
-		setup  func() func()
+		name    string
+		urlStr  string
+		headers map[string]string
+		want    bool
+		setup   func() (string, func())
 	}{
 		{
-			name:   "Valid URL with running server",
-			urlStr: "http://localhost:8080",
-			want:   true,
-			setup: func() func() {
-				// Start a TCP server on localhost:8080
-				ln, err := net.Listen("tcp", "localhost:8080")
+			name: "Valid URL with running server",
+			want: true,
+			setup: func() (string, func()) {
+				// Start a server answering the version endpoint like Ollama
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path != "/api/version" {
+						http.NotFound(w, r)
+						return
+					}
+					fmt.Fprint(w, `{"version": "0.5.1"}`)
+				}))
+				return server.URL + "/api/generate", server.Close
+			},
+		},
+		{
+			name:    "Server behind a proxy requiring a token",
+			headers: map[string]string{"Authorization": "Bearer secret"},
+			want:    true,
+			setup: func() (string, func()) {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.Header.Get("Authorization") != "Bearer secret" {
+						http.Error(w, "Unauthorized", http.StatusUnauthorized)
+						return
+					}
+					fmt.Fprint(w, `{"version": "0.5.1"}`)
+				}))
+				return server.URL + "/api/generate", server.Close
+			},
+		},
+		{
+			name: "Proxy returning 502",
+			want: false,
+			setup: func() (string, func()) {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					http.Error(w, "Bad Gateway", http.StatusBadGateway)
+				}))
+				return server.URL + "/api/generate", server.Close
+			},
+		},
+		{
+			name: "Other process listening on the port",
+			want: false,
+			setup: func() (string, func()) {
+				// Accept TCP connections without speaking HTTP
+				ln, err := net.Listen("tcp", "127.0.0.1:0")
 				if err != nil {
-					t.Fatalf("Failed to start test server: %v", err)
-				}
-				// Return a function to close the listener
-				return func() {
-					ln.Close()
+					t.Fatalf("Failed to start test listener: %v", err)
 				}
+				go func() {
+					for {
+						conn, err := ln.Accept()
+						if err != nil {
+							return
+						}
+						conn.Close()
+					}
+				}()
+				return "http://" + ln.Addr().String(), func() { ln.Close() }
 			},
 		},
 		{
 			name:   "Valid URL with no server running",
 			urlStr: "http://localhost:8081",
 			want:   false,
-			setup:  func() func() { return func() {} }, // No setup needed
 		},
 		{
 			name:   "Invalid URL",
 			urlStr: "://invalid-url",
 			want:   false,
-			setup:  func() func() { return func() {} }, // No setup needed
 		},
 		{
 			name:   "URL with empty host",
 			urlStr: "http:///path",
 			want:   false,
-			setup:  func() func() { return func() {} }, // No setup needed
 		},
 		{
 			name:   "Malformed URL",
 			urlStr: "http://",
 			want:   false,
-			setup:  func() func() { return func() {} }, // No setup needed
 		},
 	}
 
@@ -63,15 +108,19 @@ func TestIsOllamaRunning(t *testing.T) {
 		// Run each test case in a separate subtest
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup the environment if needed
-			teardown := tt.setup()
-			defer teardown()
+			urlStr := tt.urlStr
+			if tt.setup != nil {
+				var teardown func()
+				urlStr, teardown = tt.setup()
+				defer teardown()
+			}
 
 			// Call the function under test
-			got := IsOllamaRunning(tt.urlStr)
+			got := IsOllamaRunning(urlStr, tt.headers)
 
 			// Check the result
 			if got != tt.want {
-				t.Errorf("IsOllamaRunning(%q) = %v; want %v", tt.urlStr, got, tt.want)
+				t.Errorf("IsOllamaRunning(%q) = %v; want %v", urlStr, got, tt.want)
 			}
 		})
 	}